@@ -0,0 +1,260 @@
+package ask
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/umanchanda/NBA-API/players"
+	"github.com/umanchanda/NBA-API/season"
+	"github.com/umanchanda/NBA-API/stats"
+)
+
+// minPlayerScore is the lowest players.Index match score accepted as the
+// player a question is about.
+const minPlayerScore = 0.6
+
+const (
+	RoutePlayer  = "player"
+	RouteLeaders = "leaders"
+)
+
+// phrase is a run of words that means a stat, a season type or a per-game
+// rate. Longer phrases are tried first.
+type phrase struct {
+	words      []string
+	stat       string
+	seasonType string
+	per        string
+	leaders    bool
+}
+
+var phrases = func() []phrase {
+	var out []phrase
+	add := func(text string, p phrase) {
+		p.words = strings.Fields(text)
+		out = append(out, p)
+	}
+	for _, w := range []string{"points", "point", "pts", "scoring", "scored"} {
+		add(w, phrase{stat: "pts"})
+	}
+	add("ppg", phrase{stat: "pts", per: stats.PerGame})
+	for _, w := range []string{"rebounds", "rebound", "reb", "rebs", "boards", "trb"} {
+		add(w, phrase{stat: "trb"})
+	}
+	add("rpg", phrase{stat: "trb", per: stats.PerGame})
+	add("offensive rebounds", phrase{stat: "orb"})
+	add("defensive rebounds", phrase{stat: "drb"})
+	for _, w := range []string{"assists", "assist", "ast", "dimes"} {
+		add(w, phrase{stat: "ast"})
+	}
+	add("apg", phrase{stat: "ast", per: stats.PerGame})
+	for _, w := range []string{"steals", "steal", "stl"} {
+		add(w, phrase{stat: "stl"})
+	}
+	for _, w := range []string{"blocks", "block", "blk", "blocked shots"} {
+		add(w, phrase{stat: "blk"})
+	}
+	for _, w := range []string{"turnovers", "turnover", "tov"} {
+		add(w, phrase{stat: "tov"})
+	}
+	for _, w := range []string{"fouls", "personal fouls", "pf"} {
+		add(w, phrase{stat: "pf"})
+	}
+	for _, w := range []string{"minutes", "mins", "mp"} {
+		add(w, phrase{stat: "mp"})
+	}
+	for _, w := range []string{"games", "games played"} {
+		add(w, phrase{stat: "g"})
+	}
+	for _, w := range []string{"threes", "3s", "3pm", "3pt", "three pointers", "3 pointers", "three point makes"} {
+		add(w, phrase{stat: "fg3"})
+	}
+	for _, w := range []string{"3p%", "3pt%", "three point percentage", "3 point percentage", "three point %"} {
+		add(w, phrase{stat: "fg3_pct"})
+	}
+	for _, w := range []string{"fg%", "field goal percentage", "shooting percentage", "field goal %"} {
+		add(w, phrase{stat: "fg_pct"})
+	}
+	for _, w := range []string{"field goals", "fg"} {
+		add(w, phrase{stat: "fg"})
+	}
+	for _, w := range []string{"ft%", "free throw percentage", "free throw %"} {
+		add(w, phrase{stat: "ft_pct"})
+	}
+	for _, w := range []string{"free throws", "ft"} {
+		add(w, phrase{stat: "ft"})
+	}
+	for _, w := range []string{"playoffs", "playoff", "postseason"} {
+		add(w, phrase{seasonType: stats.SeasonTypePlayoffs})
+	}
+	for _, w := range []string{"regular season", "regular"} {
+		add(w, phrase{seasonType: stats.SeasonTypeRegular})
+	}
+	for _, w := range []string{"per game", "average", "averages", "averaged", "avg"} {
+		add(w, phrase{per: stats.PerGame})
+	}
+	for _, w := range []string{"per 36", "per36", "per 36 minutes"} {
+		add(w, phrase{per: stats.Per36})
+	}
+	for _, w := range []string{"leaders", "leader", "led", "leading", "most", "top", "best", "highest"} {
+		add(w, phrase{leaders: true})
+	}
+	// Longest first so "free throw percentage" wins over "free throws".
+	sort.SliceStable(out, func(i, j int) bool {
+		return len(out[i].words) > len(out[j].words)
+	})
+	return out
+}()
+
+// stopwords are dropped before the leftover words are matched to a player.
+var stopwords = map[string]bool{
+	"a": true, "an": true, "and": true, "did": true, "does": true, "during": true,
+	"for": true, "get": true, "his": true, "her": true, "how": true, "in": true,
+	"is": true, "league": true, "many": true, "me": true, "much": true, "nba": true,
+	"of": true, "season": true, "show": true, "stats": true, "the": true, "their": true,
+	"was": true, "what": true, "who": true, "with": true, "year": true,
+}
+
+// Intent is what a question was understood to ask.
+type Intent struct {
+	Route       string          `json:"route"`
+	Player      *players.Player `json:"player,omitempty"`
+	Season      string          `json:"season,omitempty"`
+	SeasonLabel string          `json:"season_label,omitempty"`
+	SeasonType  string          `json:"season_type,omitempty"`
+	Stat        string          `json:"stat,omitempty"`
+	Per         string          `json:"per,omitempty"`
+	Ignored     []string        `json:"ignored,omitempty"`
+}
+
+// Parse pulls a player, season, season type and stat out of free text such
+// as "lebron 2016 playoffs points" or "most assists 2023-24". Questions that
+// name a player are routed to the player's stats; questions without one
+// that name a stat are routed to the leaderboard.
+func Parse(text string, index *players.Index) (Intent, error) {
+	var in Intent
+	var leaders bool
+	var rest []string
+
+	words := strings.Fields(strings.ToLower(strings.Trim(text, " ?!.")))
+	for i := 0; i < len(words); {
+		w := strings.Trim(words[i], ",?!")
+		if s, err := season.Parse(w); err == nil {
+			in.Season = s.Key()
+			in.SeasonLabel = s.String()
+			i++
+			continue
+		}
+
+		matched := false
+		for _, p := range phrases {
+			if i+len(p.words) > len(words) {
+				continue
+			}
+			same := true
+			for j, pw := range p.words {
+				if strings.Trim(words[i+j], ",?!") != pw {
+					same = false
+					break
+				}
+			}
+			if !same {
+				continue
+			}
+			if p.stat != "" {
+				in.Stat = p.stat
+			}
+			if p.seasonType != "" {
+				in.SeasonType = p.seasonType
+			}
+			if p.per != "" {
+				in.Per = p.per
+			}
+			leaders = leaders || p.leaders
+			i += len(p.words)
+			matched = true
+			break
+		}
+		if matched {
+			continue
+		}
+
+		if !stopwords[w] {
+			rest = append(rest, w)
+		}
+		i++
+	}
+
+	if len(rest) > 0 && !leaders {
+		if m := index.Search(strings.Join(rest, " "), 1); len(m) > 0 && m[0].Score >= minPlayerScore {
+			p := m[0].Player
+			in.Player = &p
+			rest = nil
+		}
+	}
+	in.Ignored = rest
+
+	switch {
+	case in.Player != nil:
+		in.Route = RoutePlayer
+	case in.Stat != "":
+		in.Route = RouteLeaders
+	default:
+		return in, fmt.Errorf("could not find a player or a stat in %q", text)
+	}
+	return in, nil
+}
+
+// PlayerQuery returns the /api/player query for a player intent: the
+// player's seasons, narrowed to the stat if one was asked for.
+func (in Intent) PlayerQuery() stats.PlayerQuery {
+	q := stats.PlayerQuery{
+		PlayerID:   in.Player.ID,
+		Season:     in.Season,
+		SeasonType: in.SeasonType,
+		Limit:      stats.DefaultLimit,
+	}
+	if in.Stat != "" {
+		q.Fields = []string{"player_id", "name", "season", "season_type", "team", "g", in.Stat}
+	}
+	return q
+}
+
+// LeaderQuery returns the /api/leaders query for a leaders intent.
+func (in Intent) LeaderQuery() stats.LeaderQuery {
+	return stats.LeaderQuery{
+		Stat:       in.Stat,
+		Season:     in.Season,
+		SeasonType: in.SeasonType,
+		Per:        in.Per,
+		MinG:       -1,
+		Limit:      10,
+	}
+}
+
+// URL returns the equivalent request to /api/player or /api/leaders, so
+// users can see and refine what their question turned into.
+func (in Intent) URL() string {
+	v := url.Values{}
+	if in.Season != "" {
+		v.Set("season", in.Season)
+	}
+	if in.SeasonType != "" {
+		v.Set("season_type", in.SeasonType)
+	}
+	if in.Route == RoutePlayer {
+		v.Set("id", in.Player.ID)
+		if in.Stat != "" {
+			v.Set("fields", strings.Join(in.PlayerQuery().Fields, ","))
+		}
+		return "/api/player?" + v.Encode()
+	}
+	v.Set("stat", in.Stat)
+	if in.Per != "" {
+		v.Set("per", in.Per)
+	}
+	v.Set("limit", "10")
+	return "/api/leaders?" + v.Encode()
+}
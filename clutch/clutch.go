@@ -0,0 +1,307 @@
+// Package clutch aggregates player and team stats in clutch time: the last
+// five minutes of the fourth quarter or overtime with the score within
+// five points.
+package clutch
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/umanchanda/NBA-API/franchise"
+	"github.com/umanchanda/NBA-API/lineups"
+	"github.com/umanchanda/NBA-API/pbp"
+	"github.com/umanchanda/NBA-API/schedule"
+	"github.com/umanchanda/NBA-API/season"
+	"github.com/umanchanda/NBA-API/stats"
+)
+
+// Window and Margin define clutch time, as the NBA does.
+const (
+	Window = 300 // seconds left in the fourth quarter or an overtime
+	Margin = 5   // points either way
+)
+
+// Line is clutch production. Games, minutes and plus-minus count only time
+// on the floor; for a team, Wins and Losses count the games that had clutch
+// time.
+type Line struct {
+	Games     int      `json:"games"`
+	Minutes   float64  `json:"minutes"`
+	PTS       int      `json:"pts"`
+	FG        int      `json:"fg"`
+	FGA       int      `json:"fga"`
+	FG3       int      `json:"fg3"`
+	FG3A      int      `json:"fg3a"`
+	FT        int      `json:"ft"`
+	FTA       int      `json:"fta"`
+	FGPct     *float64 `json:"fg_pct"`
+	FG3Pct    *float64 `json:"fg3_pct"`
+	FTPct     *float64 `json:"ft_pct"`
+	EFGPct    *float64 `json:"efg_pct"`
+	AST       int      `json:"ast"`
+	TRB       int      `json:"trb"`
+	TOV       int      `json:"tov"`
+	PlusMinus int      `json:"plus_minus"`
+	Wins      *int     `json:"wins,omitempty"`
+	Losses    *int     `json:"losses,omitempty"`
+}
+
+// Clutch is the /api/player/{id}/clutch and /api/team/{code}/clutch
+// response. Missing counts games without stored play-by-play.
+type Clutch struct {
+	Player      string `json:"player,omitempty"`
+	Franchise   string `json:"franchise,omitempty"`
+	Team        string `json:"team,omitempty"`
+	Name        string `json:"name,omitempty"`
+	Season      string `json:"season"`
+	SeasonLabel string `json:"season_label"`
+	SeasonType  string `json:"season_type"`
+	Missing     int    `json:"missing"`
+	Line
+}
+
+func ratio(makes, attempts float64) *float64 {
+	if attempts == 0 {
+		return nil
+	}
+	v := math.Round(makes/attempts*1000) / 1000
+	return &v
+}
+
+// isClutch reports whether event i of a game was played in clutch time,
+// judged by the clock at the event and the score before it. The time since
+// the previous play was played at that same score, so it is clutch time by
+// the same test, from the 5:00 mark at the earliest.
+func isClutch(events []pbp.Event, i int) bool {
+	e := events[i]
+	if e.Period < 4 || e.Remaining > Window {
+		return false
+	}
+	away, home := 0, 0
+	if i > 0 {
+		away, home = events[i-1].AwayScore, events[i-1].HomeScore
+	}
+	d := away - home
+	return d >= -Margin && d <= Margin
+}
+
+// who is the player or team being followed through a game.
+type who struct {
+	player string // empty for a team
+	home   bool
+}
+
+// on reports whether w was on the floor.
+func (w who) on(f lineups.Floor) bool {
+	if w.player == "" {
+		return true
+	}
+	five := f.Away
+	if w.home {
+		five = f.Home
+	}
+	for _, id := range five {
+		if id == w.player {
+			return true
+		}
+	}
+	return false
+}
+
+// mine reports whether a play is credited to w.
+func (w who) mine(e pbp.Event) bool {
+	if w.player == "" {
+		return e.Team != "" && e.Home == w.home
+	}
+	return e.Player == w.player
+}
+
+// add counts w's clutch time in one game and reports whether the game had
+// any. The game counts toward Games only if w was on the floor for some of
+// it.
+func (l *Line) add(p pbp.PlayByPlay, w who) bool {
+	floors := lineups.Track(p)
+	var seconds float64
+	had, on := false, false
+	for i, e := range p.Events {
+		if !isClutch(p.Events, i) {
+			continue
+		}
+		had = true
+		on = on || w.on(floors[i])
+		if i > 0 && p.Events[i-1].Period == e.Period && w.on(floors[i]) {
+			seconds += math.Min(p.Events[i-1].Remaining, Window) - e.Remaining
+		}
+		if e.Team == "" {
+			continue
+		}
+		if e.Points > 0 && w.on(floors[i]) {
+			if e.Home == w.home {
+				l.PlusMinus += e.Points
+			} else {
+				l.PlusMinus -= e.Points
+			}
+		}
+		if e.Type == pbp.TypeShot && w.player != "" && e.Made != nil && *e.Made && e.Player2 == w.player && e.Home == w.home {
+			l.AST++
+		}
+		if !w.mine(e) {
+			continue
+		}
+		made := e.Made != nil && *e.Made
+		switch e.Type {
+		case pbp.TypeShot:
+			l.FGA++
+			if e.ShotValue == 3 {
+				l.FG3A++
+			}
+			if made {
+				l.FG++
+				if e.ShotValue == 3 {
+					l.FG3++
+				}
+			}
+			if made && w.player == "" && e.Player2 != "" {
+				l.AST++
+			}
+		case pbp.TypeFreeThrow:
+			l.FTA++
+			if made {
+				l.FT++
+			}
+		case pbp.TypeRebound:
+			if e.Player != "" {
+				l.TRB++
+			}
+		case pbp.TypeTurnover:
+			l.TOV++
+		}
+		l.PTS += e.Points
+	}
+	if on {
+		l.Games++
+		l.Minutes += seconds / 60
+	}
+	return had
+}
+
+func (l *Line) finish() {
+	l.Minutes = math.Round(l.Minutes*10) / 10
+	l.FGPct = ratio(float64(l.FG), float64(l.FGA))
+	l.FG3Pct = ratio(float64(l.FG3), float64(l.FG3A))
+	l.FTPct = ratio(float64(l.FT), float64(l.FTA))
+	l.EFGPct = ratio(float64(l.FG)+0.5*float64(l.FG3), float64(l.FGA))
+}
+
+// ForPlayer aggregates a player's clutch time over a season's stored
+// play-by-play, in the games the box score warehouse has them playing.
+func ForPlayer(db *sql.DB, playerID, seasonKey, seasonType string) (Clutch, error) {
+	if seasonType == "" {
+		seasonType = stats.SeasonTypeRegular
+	}
+	rows, err := db.Query(`SELECT ps.game_id, ps.team
+		FROM game_player_stats ps
+		JOIN games g ON g.game_id = ps.game_id
+		WHERE ps.player_id = $1 AND g.season = $2 AND g.season_type = $3
+		ORDER BY g.date`, playerID, seasonKey, seasonType)
+	if err != nil {
+		return Clutch{}, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+	teams := make(map[string]string)
+	var ids []string
+	for rows.Next() {
+		var id, team string
+		if err := rows.Scan(&id, &team); err != nil {
+			return Clutch{}, fmt.Errorf("scan failed: %w", err)
+		}
+		teams[id] = team
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return Clutch{}, fmt.Errorf("query failed: %w", err)
+	}
+	if len(ids) == 0 {
+		return Clutch{}, stats.ErrNotFound
+	}
+
+	games, err := pbp.LoadGames(db, ids)
+	if err != nil {
+		return Clutch{}, err
+	}
+	c := Clutch{
+		Player:      playerID,
+		Season:      seasonKey,
+		SeasonLabel: season.Label(seasonKey),
+		SeasonType:  seasonType,
+		Missing:     len(ids) - len(games),
+	}
+	for _, g := range games {
+		c.add(g, who{player: playerID, home: teams[g.GameID] == g.Home})
+	}
+	c.finish()
+	return c, nil
+}
+
+// ForTeam aggregates a team's clutch time over a season's stored
+// play-by-play, with its record in games that had clutch time.
+func ForTeam(db *sql.DB, code, seasonKey, seasonType string) (Clutch, error) {
+	f, ok := franchise.Lookup(code)
+	if !ok {
+		return Clutch{}, stats.ErrBadQuery{Err: fmt.Errorf("unknown team %q", code)}
+	}
+	year, _ := strconv.Atoi(seasonKey)
+	era, ok := f.EraFor(year)
+	if !ok {
+		return Clutch{}, stats.ErrBadQuery{Err: fmt.Errorf("%s did not play in %s", f.ID, season.Label(seasonKey))}
+	}
+	if seasonType == "" {
+		seasonType = stats.SeasonTypeRegular
+	}
+
+	results, err := schedule.Results(db, schedule.ResultQuery{
+		FromSeason: seasonKey,
+		ToSeason:   seasonKey,
+		SeasonType: seasonType,
+		Teams:      []string{era.Code},
+	})
+	if err != nil {
+		return Clutch{}, err
+	}
+	ids := make([]string, len(results))
+	for i, r := range results {
+		ids[i] = r.GameID
+	}
+	games, err := pbp.LoadGames(db, ids)
+	if err != nil {
+		return Clutch{}, err
+	}
+
+	c := Clutch{
+		Franchise:   f.ID,
+		Team:        era.Code,
+		Name:        era.Name,
+		Season:      seasonKey,
+		SeasonLabel: season.Label(seasonKey),
+		SeasonType:  seasonType,
+		Missing:     len(ids) - len(games),
+	}
+	wins, losses := 0, 0
+	for _, g := range games {
+		home := g.Home == era.Code
+		if !c.add(g, who{home: home}) {
+			continue
+		}
+		last := g.Events[len(g.Events)-1]
+		if (last.HomeScore > last.AwayScore) == home {
+			wins++
+		} else {
+			losses++
+		}
+	}
+	c.Wins, c.Losses = &wins, &losses
+	c.finish()
+	return c, nil
+}
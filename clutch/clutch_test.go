@@ -0,0 +1,91 @@
+package clutch
+
+import (
+	"testing"
+
+	"github.com/umanchanda/NBA-API/pbp"
+)
+
+func TestLineCountsOnlyClutchTime(t *testing.T) {
+	yes := true
+	shot := func(remaining float64, home bool, away, homeScore int) pbp.Event {
+		team := "BOS"
+		if home {
+			team = "NYK"
+		}
+		return pbp.Event{Period: 4, Remaining: remaining, Team: team, Home: home, Type: pbp.TypeShot,
+			Made: &yes, ShotValue: 2, Points: 2, AwayScore: away, HomeScore: homeScore}
+	}
+	p := pbp.PlayByPlay{GameID: "202403110NYK", Away: "BOS", Home: "NYK", Events: []pbp.Event{
+		{Period: 4, Remaining: 720, Type: pbp.TypePeriodStart, AwayScore: 90, HomeScore: 90},
+		shot(370, true, 90, 92),  // 6:10, before the window
+		shot(290, true, 90, 94),  // 4:50: 10 seconds of clutch time
+		shot(250, true, 90, 96),  // 4:10, within 4 before it
+		shot(200, true, 90, 98),  // up 6 before it: not clutch
+		shot(150, false, 92, 98), // up 8 before it: not clutch
+		shot(100, false, 94, 98), // up 6 before it: not clutch
+		shot(40, false, 96, 98),  // back within 4 before it: 60 seconds
+		{Period: 4, Remaining: 0, Type: pbp.TypePeriodEnd, AwayScore: 96, HomeScore: 98},
+	}}
+
+	var l Line
+	if !l.add(p, who{home: true}) {
+		t.Fatal("no clutch time found")
+	}
+	// 10s to 4:50, 40s to 4:10, 60s to 0:40 and 40s to the end.
+	if want := 150.0 / 60; l.Minutes != want {
+		t.Errorf("minutes = %v, want %v", l.Minutes, want)
+	}
+	if l.PTS != 4 || l.FGA != 2 {
+		t.Errorf("home clutch pts = %d on %d FGA, want 4 on 2", l.PTS, l.FGA)
+	}
+	if l.PlusMinus != 2 {
+		t.Errorf("home plus-minus = %d, want 2", l.PlusMinus)
+	}
+}
+
+func TestLineCountsPlayerGamesOnTheFloor(t *testing.T) {
+	yes, no := true, false
+	play := func(remaining float64, home bool, typ, player, player2 string) pbp.Event {
+		team := "BOS"
+		if home {
+			team = "NYK"
+		}
+		return pbp.Event{Period: 4, Remaining: remaining, Team: team, Home: home, Type: typ,
+			Player: player, Player2: player2, AwayScore: 90, HomeScore: 90}
+	}
+	events := []pbp.Event{{Period: 4, Remaining: 720, Type: pbp.TypePeriodStart, AwayScore: 90, HomeScore: 90}}
+	for i, id := range []string{"1", "2", "3", "4", "5"} {
+		a := play(700-float64(10*i), false, pbp.TypeShot, "a"+id, "")
+		h := play(650-float64(10*i), true, pbp.TypeShot, "h"+id, "")
+		a.Made, a.ShotValue = &no, 2
+		h.Made, h.ShotValue = &no, 2
+		events = append(events, a, h)
+	}
+	shot := play(200, true, pbp.TypeShot, "h6", "")
+	shot.Made, shot.ShotValue, shot.Points, shot.HomeScore = &yes, 2, 2, 92
+	events = append(events,
+		play(400, true, pbp.TypeSub, "h6", "h1"), // 6:40, before the window
+		shot,
+		pbp.Event{Period: 4, Remaining: 0, Type: pbp.TypePeriodEnd, AwayScore: 90, HomeScore: 92},
+	)
+	for i := range events {
+		events[i].Seq = i + 1
+	}
+	p := pbp.PlayByPlay{GameID: "202403110NYK", Away: "BOS", Home: "NYK", Events: events}
+
+	var bench Line
+	if !bench.add(p, who{player: "h1", home: true}) {
+		t.Fatal("no clutch time found")
+	}
+	if bench.Games != 0 || bench.Minutes != 0 {
+		t.Errorf("h1 sat through clutch time but has %d games and %v minutes", bench.Games, bench.Minutes)
+	}
+
+	var sub Line
+	sub.add(p, who{player: "h6", home: true})
+	if sub.Games != 1 || sub.Minutes != 5 || sub.PTS != 2 || sub.PlusMinus != 2 {
+		t.Errorf("h6 = %d games, %v minutes, %d pts, %+d; want 1, 5, 2, +2",
+			sub.Games, sub.Minutes, sub.PTS, sub.PlusMinus)
+	}
+}
@@ -0,0 +1,149 @@
+// Command backfill refreshes a season's schedule and stores every game of
+// the season in the box score warehouse, walking the season one day at a
+// time through the daily scores page. With -pbp it then stores the
+// play-by-play of every stored game.
+package main
+
+import (
+	"database/sql"
+	"flag"
+	"log"
+	"time"
+
+	"github.com/umanchanda/NBA-API/internal/db"
+	"github.com/umanchanda/NBA-API/pbp"
+	"github.com/umanchanda/NBA-API/schedule"
+	"github.com/umanchanda/NBA-API/season"
+	"github.com/umanchanda/NBA-API/teamboxscore"
+	"github.com/umanchanda/NBA-API/warehouse"
+)
+
+// days returns every date that belongs to s, from October 1st of its first
+// year (later for seasons that started late) up to yesterday, so games still
+// in progress are never stored.
+func days(s season.Season) []time.Time {
+	yesterday := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -1)
+	d := time.Date(s.Start, time.October, 1, 0, 0, 0, 0, time.UTC)
+	for season.FromDate(d) != s && d.Year() <= s.End {
+		d = d.AddDate(0, 0, 1)
+	}
+
+	var out []time.Time
+	for ; season.FromDate(d) == s && !d.After(yesterday); d = d.AddDate(0, 0, 1) {
+		out = append(out, d)
+	}
+	return out
+}
+
+// backfillDay stores every game on date that is not stored yet, and
+// returns how many it stored.
+func backfillDay(db *sql.DB, date time.Time, delay time.Duration) int {
+	scores, err := teamboxscore.Scores(date.Format("01"), date.Format("02"), date.Format("2006"))
+	time.Sleep(delay)
+	if err != nil {
+		log.Printf("[%s] scores page failed: %v", date.Format("2006-01-02"), err)
+		return 0
+	}
+
+	stored := 0
+	for _, score := range scores {
+		if score.GameID == "" {
+			continue
+		}
+		if ok, err := warehouse.Exists(db, score.GameID); err != nil || ok {
+			continue
+		}
+		g, err := warehouse.Scrape(date, score)
+		time.Sleep(delay)
+		if err != nil {
+			log.Printf("[%s] %s scrape failed: %v", date.Format("2006-01-02"), score.GameID, err)
+			continue
+		}
+		if err := warehouse.Store(db, g); err != nil {
+			log.Printf("[%s] %s store failed: %v", date.Format("2006-01-02"), score.GameID, err)
+			continue
+		}
+		stored++
+	}
+	return stored
+}
+
+// backfillPlayByPlay stores the play-by-play of every stored game of the
+// season that does not have it yet, and returns how many it stored.
+func backfillPlayByPlay(db *sql.DB, s season.Season, delay time.Duration) int {
+	ids, err := pbp.Missing(db, s.Key())
+	if err != nil {
+		log.Printf("%s play-by-play lookup failed: %v", s, err)
+		return 0
+	}
+	stored := 0
+	for _, id := range ids {
+		p, err := pbp.Scrape(id)
+		time.Sleep(delay)
+		if err != nil {
+			log.Printf("%s play-by-play scrape failed: %v", id, err)
+			continue
+		}
+		if err := pbp.Store(db, p); err != nil {
+			log.Printf("%s play-by-play store failed: %v", id, err)
+			continue
+		}
+		stored++
+	}
+	return stored
+}
+
+func main() {
+	seasonFlag := flag.String("season", "", `season to backfill, e.g. "2024" or "2023-24"`)
+	delay := flag.Duration("delay", 3*time.Second, "pause between requests to basketball-reference")
+	scheduleOnly := flag.Bool("schedule-only", false, "refresh the schedule without backfilling box scores")
+	playByPlay := flag.Bool("pbp", false, "also store the play-by-play of every stored game")
+	flag.Parse()
+
+	s, err := season.Parse(*seasonFlag)
+	if err != nil {
+		log.Fatalf("-season: %v", err)
+	}
+
+	db, err := db.Open()
+	if err != nil {
+		log.Fatalf("db connection failed: %v", err)
+	}
+	defer db.Close()
+
+	if err := warehouse.CreateTables(db); err != nil {
+		log.Fatalf("create tables failed: %v", err)
+	}
+	if err := schedule.CreateTable(db); err != nil {
+		log.Fatalf("create schedule table failed: %v", err)
+	}
+	if err := pbp.CreateTable(db); err != nil {
+		log.Fatalf("create pbp_events table failed: %v", err)
+	}
+
+	games, err := schedule.Scrape(s)
+	if err != nil {
+		log.Printf("%s schedule scrape failed: %v", s, err)
+	} else if err := schedule.Store(db, s.Key(), games); err != nil {
+		log.Printf("%s schedule store failed: %v", s, err)
+	} else {
+		log.Printf("%s schedule stored: %d games", s, len(games))
+	}
+	if *scheduleOnly {
+		return
+	}
+
+	total := 0
+	for _, date := range days(s) {
+		n := backfillDay(db, date, *delay)
+		if n > 0 {
+			log.Printf("[%s] stored %d games", date.Format("2006-01-02"), n)
+		}
+		total += n
+	}
+	log.Printf("%s backfilled: %d games stored", s, total)
+
+	if *playByPlay {
+		log.Printf("%s play-by-play stored: %d games", s, backfillPlayByPlay(db, s, *delay))
+	}
+}
@@ -0,0 +1,108 @@
+// Command fitwinprob fits the in-game win probability model on the stored
+// play-by-play and writes its coefficients as JSON, by default over the
+// model embedded in the winprob package.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/umanchanda/NBA-API/internal/db"
+	"github.com/umanchanda/NBA-API/pbp"
+	"github.com/umanchanda/NBA-API/ratings"
+	"github.com/umanchanda/NBA-API/schedule"
+	"github.com/umanchanda/NBA-API/season"
+	"github.com/umanchanda/NBA-API/winprob"
+)
+
+// seasonFlag parses an optional season flag.
+func seasonFlag(name, value string) (season.Season, bool) {
+	if value == "" {
+		return season.Season{}, false
+	}
+	s, err := season.Parse(value)
+	if err != nil {
+		log.Fatalf("-%s: %v", name, err)
+	}
+	return s, true
+}
+
+func main() {
+	fromFlag := flag.String("from", "", "first season to fit on, e.g. 2020 (default: every stored season)")
+	toFlag := flag.String("to", "", "last season to fit on (default: every stored season)")
+	out := flag.String("out", "winprob/model.json", "file to write the model to")
+	flag.Parse()
+	var from int
+	if s, ok := seasonFlag("from", *fromFlag); ok {
+		from = s.End
+	}
+	var to string
+	if s, ok := seasonFlag("to", *toFlag); ok {
+		to = s.Key()
+	}
+
+	db, err := db.Open()
+	if err != nil {
+		log.Fatalf("db connection failed: %v", err)
+	}
+	defer db.Close()
+
+	// Every earlier result is played through Elo for the pregame forecasts.
+	results, err := schedule.Results(db, schedule.ResultQuery{ToSeason: to})
+	if err != nil {
+		log.Fatalf("results query failed: %v", err)
+	}
+	e := ratings.NewElo()
+	pregame := make(map[string]float64)
+	var seasons []string
+	bySeason := make(map[string][]string)
+	for _, r := range results {
+		step, ok := e.Play(r)
+		if year, _ := strconv.Atoi(r.Season); !ok || year < from {
+			continue
+		}
+		pregame[r.GameID] = step.HomeWinProb
+		if bySeason[r.Season] == nil {
+			seasons = append(seasons, r.Season)
+		}
+		bySeason[r.Season] = append(bySeason[r.Season], r.GameID)
+	}
+
+	var samples []winprob.Sample
+	games := 0
+	for _, key := range seasons {
+		// One season at a time keeps the play-by-play in memory small.
+		plays, err := pbp.LoadGames(db, bySeason[key])
+		if err != nil {
+			log.Fatalf("%s play-by-play load failed: %v", season.Label(key), err)
+		}
+		for _, p := range plays {
+			last := p.Events[len(p.Events)-1]
+			if last.HomeScore == last.AwayScore {
+				continue
+			}
+			for _, s := range winprob.States(p, pregame[p.GameID]) {
+				samples = append(samples, winprob.Sample{State: s, HomeWon: last.HomeScore > last.AwayScore})
+			}
+		}
+		games += len(plays)
+		log.Printf("%s: %d games with play-by-play", season.Label(key), len(plays))
+	}
+
+	m, err := winprob.Fit(samples)
+	if err != nil {
+		log.Fatalf("fit failed: %v", err)
+	}
+	m.Games = games
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		log.Fatalf("encoding model failed: %v", err)
+	}
+	if err := os.WriteFile(*out, append(data, '\n'), 0o644); err != nil {
+		log.Fatalf("writing %s failed: %v", *out, err)
+	}
+	log.Printf("fit on %d games, %d plays: wrote %s", m.Games, m.Events, *out)
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"database/sql"
+	"flag"
 	"fmt"
 	"log"
 	"time"
@@ -34,15 +35,66 @@ func seedType(db *sql.DB, year int, seasonType string) {
 		players[i].Season = season
 	}
 
-	if err := database.InsertPlayers(db, players); err != nil {
+	// Clear rows left over from seeds that predate player ids. The delete
+	// and the insert share a transaction so a failed insert leaves the old
+	// rows in place.
+	tx, err := db.Begin()
+	if err != nil {
+		log.Printf("[%s/%s] could not start transaction: %v", season, seasonType, err)
+		return
+	}
+	defer tx.Rollback()
+
+	if err := database.DeleteSeason(tx, season, seasonType); err != nil {
+		log.Printf("[%s/%s] delete failed: %v", season, seasonType, err)
+		return
+	}
+
+	if err := database.InsertPlayers(tx, players); err != nil {
 		log.Printf("[%s/%s] insert failed: %v", season, seasonType, err)
 		return
 	}
 
+	if err := tx.Commit(); err != nil {
+		log.Printf("[%s/%s] commit failed: %v", season, seasonType, err)
+		return
+	}
+
 	log.Printf("[%s/%s] done (%d players)", season, seasonType, len(players))
 }
 
+// seedGameLogs scrapes the game log of every player with season totals in
+// year whose games are not stored yet. Each page holds both the regular
+// season and the playoffs.
+func seedGameLogs(db *sql.DB, year int) {
+	season := fmt.Sprintf("%d", year)
+
+	ids, err := database.GameLogPlayers(db, season)
+	if err != nil {
+		log.Printf("[%s/gamelogs] skipping — could not list players: %v", season, err)
+		return
+	}
+	log.Printf("[%s/gamelogs] %d players to fetch", season, len(ids))
+
+	for _, id := range ids {
+		games, err := database.ScrapeGameLog(id, season)
+		if err != nil {
+			log.Printf("[%s/gamelogs] %s scrape failed: %v", season, id, err)
+		} else if err := database.InsertPlayerGames(db, games); err != nil {
+			log.Printf("[%s/gamelogs] %s insert failed: %v", season, id, err)
+		}
+		// basketball-reference allows about 20 requests a minute.
+		time.Sleep(3 * time.Second)
+	}
+
+	log.Printf("[%s/gamelogs] done", season)
+}
+
 func main() {
+	gameLogs := flag.Bool("gamelogs", false, "also backfill player game logs (one request per player-season)")
+	from := flag.Int("from", firstSeason, "first season to seed, by end year")
+	flag.Parse()
+
 	db, err := database.ConnectToDB()
 	if err != nil {
 		log.Fatalf("db connection failed: %v", err)
@@ -55,12 +107,16 @@ func main() {
 
 	currentYear := time.Now().Year()
 
-	for year := firstSeason; year <= currentYear; year++ {
+	for year := *from; year <= currentYear; year++ {
 		seedType(db, year, database.SeasonTypeRegular)
 		time.Sleep(2 * time.Second)
 
 		seedType(db, year, database.SeasonTypePlayoffs)
 		time.Sleep(2 * time.Second)
+
+		if *gameLogs {
+			seedGameLogs(db, year)
+		}
 	}
 
 	log.Println("all seasons seeded")
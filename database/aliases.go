@@ -0,0 +1,45 @@
+package database
+
+// defaultAliases maps well-known nicknames to basketball-reference player ids.
+// They are inserted into player_aliases on CreateTable; more can be added to
+// the table by hand.
+var defaultAliases = map[string]string{
+	"ad":                  "davisan02",
+	"big baby":            "davisgl01",
+	"black mamba":         "bryanko01",
+	"cp3":                 "paulch01",
+	"d-wade":              "wadedw01",
+	"dame":                "lillada01",
+	"flash":               "wadedw01",
+	"greek freak":         "antetgi01",
+	"his airness":         "jordami01",
+	"joker":               "jokicni01",
+	"kat":                 "townska01",
+	"kd":                  "duranke01",
+	"kg":                  "garneke01",
+	"king james":          "jamesle01",
+	"mailman":             "malonka01",
+	"mj":                  "jordami01",
+	"melo":                "anthoca01",
+	"penny":               "hardaan01",
+	"pg13":                "georgpa01",
+	"sga":                 "gilgesh01",
+	"shaq":                "onealsh01",
+	"sir charles":         "barklch01",
+	"slim reaper":         "duranke01",
+	"t-mac":               "mcgratr01",
+	"the admiral":         "robinda01",
+	"the answer":          "iversal01",
+	"the beard":           "hardeja01",
+	"the big fundamental": "duncati01",
+	"the big ticket":      "garneke01",
+	"the brow":            "davisan02",
+	"the dream":           "olajuha01",
+	"the glove":           "paytoga01",
+	"the process":         "embiijo01",
+	"the truth":           "piercpa01",
+	"the worm":            "rodmade01",
+	"vinsanity":           "cartevi01",
+	"wemby":               "wembavi01",
+	"zo":                  "mournal01",
+}
@@ -30,6 +30,7 @@ func connStr() string {
 
 // NBAPlayer holds season totals for a single player from basketball-reference.
 type NBAPlayer struct {
+	PlayerID   string `json:"player_id,omitempty"`
 	Season     string `json:"season,omitempty"`
 	SeasonType string `json:"season_type,omitempty"`
 	Name       string `json:"name,omitempty"`
@@ -70,6 +71,7 @@ func ConnectToDB() (*sql.DB, error) {
 func CreateTable(db *sql.DB) error {
 	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS playerstats (
 		id          SERIAL PRIMARY KEY,
+		player_id   TEXT,
 		season      TEXT,
 		season_type TEXT,
 		name        TEXT,
@@ -102,7 +104,60 @@ func CreateTable(db *sql.DB) error {
 		return err
 	}
 
+	// player_id was added after the first seasons were seeded.
+	if _, err := db.Exec(`ALTER TABLE playerstats ADD COLUMN IF NOT EXISTS player_id TEXT`); err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS player_aliases (
+		alias     TEXT PRIMARY KEY,
+		player_id TEXT NOT NULL
+	)`)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS player_games (
+		player_id   TEXT NOT NULL,
+		game_id     TEXT NOT NULL,
+		season      TEXT NOT NULL,
+		season_type TEXT NOT NULL,
+		date        DATE NOT NULL,
+		team        TEXT NOT NULL,
+		opp         TEXT NOT NULL,
+		home        BOOLEAN NOT NULL,
+		result      TEXT,
+		margin      INTEGER,
+		team_pts    INTEGER,
+		opp_pts     INTEGER,
+		started     BOOLEAN NOT NULL,
+		seconds     INTEGER NOT NULL,
+		fg          INTEGER NOT NULL,
+		fga         INTEGER NOT NULL,
+		fg3         INTEGER NOT NULL,
+		fg3a        INTEGER NOT NULL,
+		ft          INTEGER NOT NULL,
+		fta         INTEGER NOT NULL,
+		orb         INTEGER NOT NULL,
+		drb         INTEGER NOT NULL,
+		trb         INTEGER NOT NULL,
+		ast         INTEGER NOT NULL,
+		stl         INTEGER NOT NULL,
+		blk         INTEGER NOT NULL,
+		tov         INTEGER NOT NULL,
+		pf          INTEGER NOT NULL,
+		pts         INTEGER NOT NULL,
+		game_score  REAL,
+		plus_minus  INTEGER,
+		PRIMARY KEY (player_id, game_id)
+	)`)
+	if err != nil {
+		return err
+	}
+
 	indexes := []string{
+		`CREATE INDEX IF NOT EXISTS idx_player_games_season ON player_games (player_id, season, date)`,
+		`CREATE INDEX IF NOT EXISTS idx_playerstats_player_id ON playerstats (player_id)`,
 		`CREATE INDEX IF NOT EXISTS idx_playerstats_name ON playerstats (LOWER(name))`,
 		`CREATE INDEX IF NOT EXISTS idx_playerstats_season ON playerstats (season)`,
 		`CREATE INDEX IF NOT EXISTS idx_playerstats_season_type ON playerstats (season_type)`,
@@ -112,28 +167,41 @@ func CreateTable(db *sql.DB) error {
 			return err
 		}
 	}
+
+	for alias, id := range defaultAliases {
+		_, err := db.Exec(`INSERT INTO player_aliases (alias, player_id) VALUES ($1, $2)
+			ON CONFLICT (alias) DO NOTHING`, alias, id)
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-func InsertPlayers(db *sql.DB, players []NBAPlayer) error {
+// InsertPlayers stores season totals inside tx, so a failed insert can be
+// rolled back together with the DeleteSeason before it.
+func InsertPlayers(tx *sql.Tx, players []NBAPlayer) error {
 	stmt := `INSERT INTO playerstats (
 		season, season_type, name, team, pos, age, g, gs, mp,
 		fg, fga, fg_pct, fg3, fg3a, fg3_pct,
 		ft, fta, ft_pct, orb, drb, trb,
-		ast, stl, blk, tov, pf, pts
+		ast, stl, blk, tov, pf, pts,
+		player_id
 	) VALUES (
 		$1,$2,$3,$4,$5,$6,$7,$8,$9,
 		$10,$11,$12,$13,$14,$15,
 		$16,$17,$18,$19,$20,$21,
-		$22,$23,$24,$25,$26,$27
+		$22,$23,$24,$25,$26,$27,
+		$28
 	)`
 
 	for _, p := range players {
-		_, err := db.Exec(stmt,
+		_, err := tx.Exec(stmt,
 			p.Season, p.SeasonType, p.Name, p.Team, p.Pos, p.Age, p.G, p.GS, p.MP,
 			p.FG, p.FGA, p.FGPct, p.FG3, p.FG3A, p.FG3Pct,
 			p.FT, p.FTA, p.FTPct, p.ORB, p.DRB, p.TRB,
 			p.AST, p.STL, p.BLK, p.TOV, p.PF, p.PTS,
+			p.PlayerID,
 		)
 		if err != nil {
 			return fmt.Errorf("insert failed for %s: %w", p.Name, err)
@@ -142,11 +210,24 @@ func InsertPlayers(db *sql.DB, players []NBAPlayer) error {
 	return nil
 }
 
+// SeasonExists reports whether a season has been seeded. Seasons seeded before
+// player ids were scraped do not count, so they get picked up again.
 func SeasonExists(db *sql.DB, year, seasonType string) (bool, error) {
 	var count int
 	err := db.QueryRow(
-		`SELECT COUNT(*) FROM playerstats WHERE season = $1 AND season_type = $2`,
+		`SELECT COUNT(*) FROM playerstats
+		WHERE season = $1 AND season_type = $2 AND player_id IS NOT NULL`,
 		year, seasonType,
 	).Scan(&count)
 	return count > 0, err
 }
+
+// DeleteSeason removes every row for a season inside tx so it can be
+// re-seeded.
+func DeleteSeason(tx *sql.Tx, year, seasonType string) error {
+	_, err := tx.Exec(
+		`DELETE FROM playerstats WHERE season = $1 AND season_type = $2`,
+		year, seasonType,
+	)
+	return err
+}
@@ -0,0 +1,255 @@
+package database
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// PlayerGame is one line of a player's game log.
+type PlayerGame struct {
+	PlayerID   string
+	GameID     string // basketball-reference box score id, e.g. "202310240DEN"
+	Season     string
+	SeasonType string
+	Date       string // YYYY-MM-DD
+	Team       string
+	Opp        string
+	Home       bool
+	Result     string // "W" or "L"
+	Margin     int
+	TeamPts    *int // unknown on pages that only give the margin
+	OppPts     *int
+	Started    bool
+	Seconds    int
+	FG         int
+	FGA        int
+	FG3        int
+	FG3A       int
+	FT         int
+	FTA        int
+	ORB        int
+	DRB        int
+	TRB        int
+	AST        int
+	STL        int
+	BLK        int
+	TOV        int
+	PF         int
+	PTS        int
+	GameScore  *float64
+	PlusMinus  *int
+}
+
+// gameLogTables are the game log table ids for each season type, before and
+// after basketball-reference's 2025 redesign.
+var gameLogTables = map[string][]string{
+	SeasonTypeRegular:  {"pgl_basic", "player_game_log_reg"},
+	SeasonTypePlayoffs: {"pgl_basic_playoffs", "player_game_log_post"},
+}
+
+var (
+	boxScoreHref = regexp.MustCompile(`/boxscores/(\w+)\.html`)
+	resultScore  = regexp.MustCompile(`^([WL])\D*(\d+)-(\d+)`)
+	resultMargin = regexp.MustCompile(`^([WL])\s*\(([+-]?\d+)\)`)
+)
+
+// fetchDocument fetches a basketball-reference page and parses it, with the
+// tables the site hides in HTML comments uncommented.
+func fetchDocument(url string) (*goquery.Document, error) {
+	log.Printf("fetching %s", url)
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("fetching %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d for %s", resp.StatusCode, url)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", url, err)
+	}
+	body = bytes.ReplaceAll(body, []byte("<!--"), nil)
+	body = bytes.ReplaceAll(body, []byte("-->"), nil)
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("parsing HTML: %w", err)
+	}
+	return doc, nil
+}
+
+// cellInt reads the first of the named cells as an int, 0 if blank.
+func cellInt(row *goquery.Selection, stats ...string) int {
+	n, _ := strconv.Atoi(coalesce(row, stats...))
+	return n
+}
+
+// seconds converts a "35:12" minutes played cell to seconds.
+func seconds(mp string) int {
+	m, s, _ := strings.Cut(mp, ":")
+	mins, _ := strconv.Atoi(m)
+	secs, _ := strconv.Atoi(s)
+	return mins*60 + secs
+}
+
+// parseResult reads a game result written "W (+12)" on older pages or
+// "W, 119-107" on newer ones.
+func parseResult(g *PlayerGame, text string) {
+	text = strings.TrimSpace(text)
+	if m := resultScore.FindStringSubmatch(text); m != nil {
+		team, _ := strconv.Atoi(m[2])
+		opp, _ := strconv.Atoi(m[3])
+		g.Result, g.TeamPts, g.OppPts, g.Margin = m[1], &team, &opp, team-opp
+		return
+	}
+	if m := resultMargin.FindStringSubmatch(text); m != nil {
+		g.Result = m[1]
+		g.Margin, _ = strconv.Atoi(m[2])
+	}
+}
+
+// ScrapeGameLog fetches every regular-season and playoff game a player
+// played in the season ending in year. Games the player missed are left out.
+func ScrapeGameLog(playerID, year string) ([]PlayerGame, error) {
+	if playerID == "" {
+		return nil, fmt.Errorf("empty player id")
+	}
+	url := baseURL + "/players/" + playerID[:1] + "/" + playerID + "/gamelog/" + year
+	doc, err := fetchDocument(url)
+	if err != nil {
+		return nil, err
+	}
+
+	var games []PlayerGame
+	for _, seasonType := range []string{SeasonTypeRegular, SeasonTypePlayoffs} {
+		for _, id := range gameLogTables[seasonType] {
+			doc.Find("table#" + id + " tbody tr").Each(func(_ int, row *goquery.Selection) {
+				if row.HasClass("thead") {
+					return
+				}
+				mp := coalesce(row, "mp")
+				href, _ := row.Find("[data-stat='date_game'] a, [data-stat='date'] a").Attr("href")
+				m := boxScoreHref.FindStringSubmatch(href)
+				if m == nil || mp == "" {
+					return
+				}
+
+				g := PlayerGame{
+					PlayerID:   playerID,
+					GameID:     m[1],
+					Season:     year,
+					SeasonType: seasonType,
+					Date:       coalesce(row, "date_game", "date"),
+					Team:       coalesce(row, "team_id", "team_name_abbr"),
+					Opp:        coalesce(row, "opp_id", "opp_name_abbr"),
+					Home:       coalesce(row, "game_location") != "@",
+					Seconds:    seconds(mp),
+					FG:         cellInt(row, "fg"),
+					FGA:        cellInt(row, "fga"),
+					FG3:        cellInt(row, "fg3"),
+					FG3A:       cellInt(row, "fg3a"),
+					FT:         cellInt(row, "ft"),
+					FTA:        cellInt(row, "fta"),
+					ORB:        cellInt(row, "orb"),
+					DRB:        cellInt(row, "drb"),
+					TRB:        cellInt(row, "trb"),
+					AST:        cellInt(row, "ast"),
+					STL:        cellInt(row, "stl"),
+					BLK:        cellInt(row, "blk"),
+					TOV:        cellInt(row, "tov"),
+					PF:         cellInt(row, "pf"),
+					PTS:        cellInt(row, "pts"),
+				}
+				started := coalesce(row, "gs", "is_starter")
+				g.Started = started == "1" || started == "*"
+				parseResult(&g, coalesce(row, "game_result"))
+				if v, err := strconv.ParseFloat(coalesce(row, "game_score"), 64); err == nil {
+					g.GameScore = &v
+				}
+				if v, err := strconv.Atoi(strings.TrimPrefix(coalesce(row, "plus_minus"), "+")); err == nil {
+					g.PlusMinus = &v
+				}
+				games = append(games, g)
+			})
+		}
+	}
+
+	log.Printf("scraped %d games for %s in %s", len(games), playerID, year)
+	return games, nil
+}
+
+// InsertPlayerGames stores game log lines, replacing any already stored for
+// the same player and game.
+func InsertPlayerGames(db *sql.DB, games []PlayerGame) error {
+	stmt := `INSERT INTO player_games (
+		player_id, game_id, season, season_type, date, team, opp, home,
+		result, margin, team_pts, opp_pts, started, seconds,
+		fg, fga, fg3, fg3a, ft, fta, orb, drb, trb,
+		ast, stl, blk, tov, pf, pts, game_score, plus_minus
+	) VALUES (
+		$1,$2,$3,$4,$5,$6,$7,$8,
+		$9,$10,$11,$12,$13,$14,
+		$15,$16,$17,$18,$19,$20,$21,$22,$23,
+		$24,$25,$26,$27,$28,$29,$30,$31
+	)
+	ON CONFLICT (player_id, game_id) DO UPDATE SET
+		season = EXCLUDED.season, season_type = EXCLUDED.season_type, date = EXCLUDED.date,
+		team = EXCLUDED.team, opp = EXCLUDED.opp, home = EXCLUDED.home,
+		result = EXCLUDED.result, margin = EXCLUDED.margin,
+		team_pts = EXCLUDED.team_pts, opp_pts = EXCLUDED.opp_pts,
+		started = EXCLUDED.started, seconds = EXCLUDED.seconds,
+		fg = EXCLUDED.fg, fga = EXCLUDED.fga, fg3 = EXCLUDED.fg3, fg3a = EXCLUDED.fg3a,
+		ft = EXCLUDED.ft, fta = EXCLUDED.fta, orb = EXCLUDED.orb, drb = EXCLUDED.drb,
+		trb = EXCLUDED.trb, ast = EXCLUDED.ast, stl = EXCLUDED.stl, blk = EXCLUDED.blk,
+		tov = EXCLUDED.tov, pf = EXCLUDED.pf, pts = EXCLUDED.pts,
+		game_score = EXCLUDED.game_score, plus_minus = EXCLUDED.plus_minus`
+
+	for _, g := range games {
+		_, err := db.Exec(stmt,
+			g.PlayerID, g.GameID, g.Season, g.SeasonType, g.Date, g.Team, g.Opp, g.Home,
+			g.Result, g.Margin, g.TeamPts, g.OppPts, g.Started, g.Seconds,
+			g.FG, g.FGA, g.FG3, g.FG3A, g.FT, g.FTA, g.ORB, g.DRB, g.TRB,
+			g.AST, g.STL, g.BLK, g.TOV, g.PF, g.PTS, g.GameScore, g.PlusMinus,
+		)
+		if err != nil {
+			return fmt.Errorf("insert failed for %s in %s: %w", g.PlayerID, g.GameID, err)
+		}
+	}
+	return nil
+}
+
+// GameLogPlayers returns the ids of players with season totals for year
+// whose game logs have not been stored yet.
+func GameLogPlayers(db *sql.DB, year string) ([]string, error) {
+	rows, err := db.Query(`SELECT DISTINCT s.player_id
+		FROM playerstats s
+		WHERE s.season = $1 AND s.season_type = $2 AND s.player_id IS NOT NULL
+			AND NOT EXISTS (
+				SELECT 1 FROM player_games g WHERE g.player_id = s.player_id AND g.season = s.season
+			)
+		ORDER BY s.player_id`, year, SeasonTypeRegular)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
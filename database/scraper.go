@@ -24,6 +24,20 @@ func coalesce(row *goquery.Selection, stats ...string) string {
 	return ""
 }
 
+// playerID returns the basketball-reference id (e.g. "jamesle01") for a name
+// cell, preferring data-append-csv and falling back to the player link.
+func playerID(cell *goquery.Selection) string {
+	if id, ok := cell.Attr("data-append-csv"); ok && id != "" {
+		return id
+	}
+	href, _ := cell.Find("a").Attr("href")
+	href = strings.TrimSuffix(href, ".html")
+	if i := strings.LastIndex(href, "/"); i >= 0 {
+		return href[i+1:]
+	}
+	return ""
+}
+
 // ScrapeTotals fetches player totals for the given year and season type.
 func ScrapeTotals(year, seasonType string) ([]NBAPlayer, error) {
 	var url string
@@ -64,15 +78,17 @@ func ScrapeTotals(year, seasonType string) ([]NBAPlayer, error) {
 			return strings.TrimSpace(row.Find("td[data-stat='" + name + "']").Text())
 		}
 
-		name := strings.TrimSpace(row.Find("td[data-stat='name_display'] a").Text())
-		if name == "" {
-			name = strings.TrimSpace(row.Find("td[data-stat='player'] a").Text())
+		nameCell := row.Find("td[data-stat='name_display']")
+		if nameCell.Length() == 0 {
+			nameCell = row.Find("td[data-stat='player']")
 		}
+		name := strings.TrimSpace(nameCell.Find("a").Text())
 		if name == "" {
 			return
 		}
 
 		players = append(players, NBAPlayer{
+			PlayerID:   playerID(nameCell),
 			SeasonType: seasonType,
 			Name:       name,
 			Team:       coalesce(row, "team_name_abbr", "team_id"),
@@ -0,0 +1,368 @@
+package filter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Limits on what Parse accepts, so a request cannot make the parser or the
+// generated SQL arbitrarily large.
+const (
+	maxLength = 1000
+	maxDepth  = 20
+)
+
+// Resolver maps a column name in an expression to the SQL that reads it and
+// whether it is numeric. ok is false for columns the caller does not expose.
+type Resolver func(name string) (expr string, numeric bool, ok bool)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokNumber
+	tokString
+	tokOp
+	tokLParen
+	tokRParen
+	tokComma
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// lex splits s into tokens. Bare words are identifiers (columns, keywords
+// or unquoted values such as playoffs); strings may use either quote.
+func lex(s string) ([]token, error) {
+	var toks []token
+	for i := 0; i < len(s); {
+		c, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case unicode.IsSpace(c):
+			i += size
+		case c == '(':
+			toks = append(toks, token{tokLParen, "(", i})
+			i++
+		case c == ')':
+			toks = append(toks, token{tokRParen, ")", i})
+			i++
+		case c == ',':
+			toks = append(toks, token{tokComma, ",", i})
+			i++
+		case c == '\'' || c == '"':
+			end := strings.IndexRune(s[i+1:], c)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated string at %d", i)
+			}
+			toks = append(toks, token{tokString, s[i+1 : i+1+end], i})
+			i += end + 2
+		case strings.ContainsRune("<>=!~", c):
+			j := i + 1
+			if j < len(s) && strings.ContainsRune("=>", rune(s[j])) {
+				j++
+			}
+			op := s[i:j]
+			switch op {
+			case "=", "!=", "<>", "<", "<=", ">", ">=", "~":
+			default:
+				return nil, fmt.Errorf("unknown operator %q at %d", op, i)
+			}
+			toks = append(toks, token{tokOp, op, i})
+			i = j
+		case c == '.' || c == '-' || unicode.IsDigit(c):
+			j := i + 1
+			for j < len(s) && (s[j] == '.' || unicode.IsDigit(rune(s[j]))) {
+				j++
+			}
+			if _, err := strconv.ParseFloat(s[i:j], 64); err != nil {
+				return nil, fmt.Errorf("bad number %q at %d", s[i:j], i)
+			}
+			toks = append(toks, token{tokNumber, s[i:j], i})
+			i = j
+		case unicode.IsLetter(c) || c == '_':
+			j := i + size
+			for j < len(s) {
+				r, n := utf8.DecodeRuneInString(s[j:])
+				if r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+					break
+				}
+				j += n
+			}
+			toks = append(toks, token{tokIdent, s[i:j], i})
+			i = j
+		default:
+			return nil, fmt.Errorf("unexpected %q at %d", c, i)
+		}
+	}
+	return append(toks, token{tokEOF, "", len(s)}), nil
+}
+
+// Filter is a parsed expression such as
+//
+//	pts >= 2000 AND fg3_pct > .400 AND (season >= 2010 OR team IN (LAL, BOS))
+//
+// Expressions combine comparisons with AND, OR, NOT and parentheses.
+// Comparisons are column op value, with op one of = != <> < <= > >= and ~
+// (case-insensitive contains), or column IN (value, ...). Keywords are
+// case-insensitive, as are comparisons on text columns.
+type Filter struct {
+	root node
+}
+
+type node interface {
+	sql(c *compiler) (string, error)
+}
+
+type boolNode struct {
+	op          string // AND or OR
+	left, right node
+}
+
+type notNode struct{ x node }
+
+type cmpNode struct {
+	column string
+	op     string // a comparison operator or IN
+	values []token
+	pos    int
+}
+
+type parser struct {
+	toks  []token
+	i     int
+	depth int
+}
+
+func (p *parser) peek() token { return p.toks[p.i] }
+
+func (p *parser) next() token {
+	t := p.toks[p.i]
+	if t.kind != tokEOF {
+		p.i++
+	}
+	return t
+}
+
+func (p *parser) keyword(kw string) bool {
+	t := p.peek()
+	if t.kind == tokIdent && strings.EqualFold(t.text, kw) {
+		p.i++
+		return true
+	}
+	return false
+}
+
+// Parse parses an expression. It checks syntax only; columns are checked
+// against a Resolver by SQL.
+func Parse(s string) (*Filter, error) {
+	if len(s) > maxLength {
+		return nil, fmt.Errorf("expression longer than %d characters", maxLength)
+	}
+	toks, err := lex(s)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{toks: toks}
+	root, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, fmt.Errorf("unexpected %q at %d", t.text, t.pos)
+	}
+	return &Filter{root: root}, nil
+}
+
+func (p *parser) or() (node, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("OR") {
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		left = boolNode{"OR", left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) and() (node, error) {
+	left, err := p.not()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("AND") {
+		right, err := p.not()
+		if err != nil {
+			return nil, err
+		}
+		left = boolNode{"AND", left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) not() (node, error) {
+	if p.keyword("NOT") {
+		x, err := p.not()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{x}, nil
+	}
+	return p.primary()
+}
+
+func (p *parser) primary() (node, error) {
+	if p.peek().kind == tokLParen {
+		p.next()
+		if p.depth++; p.depth > maxDepth {
+			return nil, fmt.Errorf("expression nested more than %d deep", maxDepth)
+		}
+		x, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if t := p.next(); t.kind != tokRParen {
+			return nil, fmt.Errorf("expected ) at %d", t.pos)
+		}
+		p.depth--
+		return x, nil
+	}
+
+	col := p.next()
+	if col.kind != tokIdent {
+		return nil, fmt.Errorf("expected a column name at %d", col.pos)
+	}
+	if p.keyword("IN") {
+		if t := p.next(); t.kind != tokLParen {
+			return nil, fmt.Errorf("expected ( after IN at %d", t.pos)
+		}
+		var values []token
+		for {
+			v, err := p.value()
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, v)
+			t := p.next()
+			if t.kind == tokRParen {
+				break
+			}
+			if t.kind != tokComma {
+				return nil, fmt.Errorf("expected , or ) at %d", t.pos)
+			}
+		}
+		return cmpNode{column: col.text, op: "IN", values: values, pos: col.pos}, nil
+	}
+
+	op := p.next()
+	if op.kind != tokOp {
+		return nil, fmt.Errorf("expected an operator after %s at %d", col.text, op.pos)
+	}
+	v, err := p.value()
+	if err != nil {
+		return nil, err
+	}
+	return cmpNode{column: col.text, op: op.text, values: []token{v}, pos: col.pos}, nil
+}
+
+func (p *parser) value() (token, error) {
+	t := p.next()
+	switch t.kind {
+	case tokNumber, tokString, tokIdent:
+		return t, nil
+	}
+	return t, fmt.Errorf("expected a value at %d", t.pos)
+}
+
+type compiler struct {
+	resolve Resolver
+	args    []interface{}
+	next    int
+}
+
+func (c *compiler) param(v interface{}) string {
+	c.args = append(c.args, v)
+	return "$" + strconv.Itoa(c.next+len(c.args)-1)
+}
+
+// SQL renders f as a SQL condition. Every value becomes a bind parameter,
+// numbered from first; the values are returned in order.
+func (f *Filter) SQL(resolve Resolver, first int) (string, []interface{}, error) {
+	c := &compiler{resolve: resolve, next: first}
+	s, err := f.root.sql(c)
+	if err != nil {
+		return "", nil, err
+	}
+	return s, c.args, nil
+}
+
+func (n boolNode) sql(c *compiler) (string, error) {
+	l, err := n.left.sql(c)
+	if err != nil {
+		return "", err
+	}
+	r, err := n.right.sql(c)
+	if err != nil {
+		return "", err
+	}
+	return "(" + l + " " + n.op + " " + r + ")", nil
+}
+
+func (n notNode) sql(c *compiler) (string, error) {
+	x, err := n.x.sql(c)
+	if err != nil {
+		return "", err
+	}
+	return "(NOT " + x + ")", nil
+}
+
+// likeEscaper escapes LIKE wildcards so ~ matches them literally.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+func (n cmpNode) sql(c *compiler) (string, error) {
+	expr, numeric, ok := c.resolve(strings.ToLower(n.column))
+	if !ok {
+		return "", fmt.Errorf("unknown column %q at %d", n.column, n.pos)
+	}
+
+	params := make([]string, len(n.values))
+	for i, v := range n.values {
+		if numeric {
+			f, err := strconv.ParseFloat(v.text, 64)
+			if err != nil {
+				return "", fmt.Errorf("%s needs a number, got %q at %d", n.column, v.text, v.pos)
+			}
+			params[i] = c.param(f)
+		} else if n.op == "~" {
+			params[i] = c.param("%" + likeEscaper.Replace(strings.ToLower(v.text)) + "%")
+		} else {
+			params[i] = c.param(strings.ToLower(v.text))
+		}
+	}
+	if !numeric {
+		expr = "LOWER(" + expr + ")"
+	}
+
+	switch n.op {
+	case "IN":
+		return expr + " IN (" + strings.Join(params, ", ") + ")", nil
+	case "~":
+		if numeric {
+			return "", fmt.Errorf("~ only applies to text columns, not %s", n.column)
+		}
+		return expr + " LIKE " + params[0] + ` ESCAPE '\'`, nil
+	case "!=":
+		return expr + " <> " + params[0], nil
+	}
+	return expr + " " + n.op + " " + params[0], nil
+}
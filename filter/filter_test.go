@@ -0,0 +1,124 @@
+package filter
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func resolve(name string) (string, bool, bool) {
+	switch name {
+	case "pts":
+		return "p.pts", true, true
+	case "fg3_pct":
+		return "p.fg3_pct", true, true
+	case "team":
+		return "p.team", false, true
+	case "name":
+		return "p.name", false, true
+	}
+	return "", false, false
+}
+
+func TestLex(t *testing.T) {
+	toks, err := lex(`pts>=2000 AND team IN ('LAL', "BOS") OR fg3_pct <> -.4`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, tok := range toks {
+		got = append(got, tok.text)
+	}
+	want := []string{"pts", ">=", "2000", "AND", "team", "IN", "(", "LAL", ",", "BOS", ")", "OR", "fg3_pct", "<>", "-.4", ""}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("tokens = %q, want %q", got, want)
+	}
+
+	toks, err = lex("name ~ Jokić")
+	if err != nil || len(toks) != 4 || toks[2].text != "Jokić" {
+		t.Errorf("lex of a non-ASCII word = %v, %v", toks, err)
+	}
+
+	for _, bad := range []string{`team = 'LAL`, `pts = 1 ·`, `pts => 1`, `pts = 1..2`, `pts; DROP TABLE players`, `pts = 1 -- comment`} {
+		if _, err := lex(bad); err == nil {
+			t.Errorf("lex(%q) succeeded", bad)
+		}
+	}
+}
+
+func TestSQL(t *testing.T) {
+	tests := []struct {
+		expr string
+		sql  string
+		args []interface{}
+	}{
+		{"pts >= 2000", "p.pts >= $3", []interface{}{2000.0}},
+		{"team = lal", "LOWER(p.team) = $3", []interface{}{"lal"}},
+		{"team ~ 'A'", `LOWER(p.team) LIKE $3 ESCAPE '\'`, []interface{}{"%a%"}},
+		{`name ~ "Jokić"`, `LOWER(p.name) LIKE $3 ESCAPE '\'`, []interface{}{"%jokić%"}},
+		{"name = Dončić", "LOWER(p.name) = $3", []interface{}{"dončić"}},
+		// LIKE wildcards in a ~ value match literally.
+		{`name ~ "100%_a\b"`, `LOWER(p.name) LIKE $3 ESCAPE '\'`, []interface{}{`%100\%\_a\\b%`}},
+		{"pts != 10", "p.pts <> $3", []interface{}{10.0}},
+		{
+			"pts > 1 and not (team in (LAL, BOS) or fg3_pct < .3)",
+			"(p.pts > $3 AND (NOT (LOWER(p.team) IN ($4, $5) OR p.fg3_pct < $6)))",
+			[]interface{}{1.0, "lal", "bos", 0.3},
+		},
+		// Values are always bound, never spliced into the SQL.
+		{
+			`team = "x' OR '1'='1"`,
+			"LOWER(p.team) = $3",
+			[]interface{}{"x' or '1'='1"},
+		},
+	}
+	for _, tt := range tests {
+		f, err := Parse(tt.expr)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.expr, err)
+			continue
+		}
+		sql, args, err := f.SQL(resolve, 3)
+		if err != nil {
+			t.Errorf("SQL(%q): %v", tt.expr, err)
+			continue
+		}
+		if sql != tt.sql || !reflect.DeepEqual(args, tt.args) {
+			t.Errorf("%q: got %s %v, want %s %v", tt.expr, sql, args, tt.sql, tt.args)
+		}
+	}
+}
+
+func TestRejects(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string // part of the error
+	}{
+		{strings.Repeat("pts > 1 AND ", 100) + "pts > 1", "longer than"},
+		{strings.Repeat("(", maxDepth+1) + "pts > 1" + strings.Repeat(")", maxDepth+1), "nested more than"},
+		{"pts >", "expected a value"},
+		{"pts 10", "expected an operator"},
+		{"(pts > 1", "expected )"},
+		{"pts > 1 pts", "unexpected"},
+		{"team IN (LAL BOS)", "expected , or )"},
+		{"password = 'x'", "unknown column"},
+		{"p.pts > 1", "bad number"},
+		{"pts > 1; DELETE FROM players", "unexpected"},
+		{"pts = LAL", "needs a number"},
+		{"pts ~ 10", "only applies to text"},
+	}
+	for _, tt := range tests {
+		f, err := Parse(tt.expr)
+		if err == nil {
+			_, _, err = f.SQL(resolve, 1)
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%.40q: error %v, want one containing %q", tt.expr, err, tt.want)
+		}
+	}
+
+	deep := strings.Repeat("(", maxDepth) + "pts > 1" + strings.Repeat(")", maxDepth)
+	if _, err := Parse(deep); err != nil {
+		t.Errorf("nesting %d deep: %v", maxDepth, err)
+	}
+}
@@ -0,0 +1,148 @@
+package franchise
+
+import "strings"
+
+// Era is a span of seasons, by end year, a franchise played under one
+// basketball-reference team code. To is 0 for the current code.
+type Era struct {
+	Code string `json:"code"`
+	Name string `json:"name"`
+	From int    `json:"from"`
+	To   int    `json:"to,omitempty"`
+}
+
+// Franchise is a team across relocations and renames, identified by its
+// current code.
+type Franchise struct {
+	ID   string `json:"id"`
+	Eras []Era  `json:"eras"`
+}
+
+// franchises lists every current NBA franchise with the codes it has used
+// since 1947, oldest first.
+var franchises = []Franchise{
+	{"ATL", []Era{{"TRI", "Tri-Cities Blackhawks", 1950, 1951}, {"MLH", "Milwaukee Hawks", 1952, 1955}, {"STL", "St. Louis Hawks", 1956, 1968}, {"ATL", "Atlanta Hawks", 1969, 0}}},
+	{"BOS", []Era{{"BOS", "Boston Celtics", 1947, 0}}},
+	{"BRK", []Era{{"NJA", "New Jersey Americans", 1968, 1968}, {"NYA", "New York Nets", 1969, 1977}, {"NJN", "New Jersey Nets", 1978, 2012}, {"BRK", "Brooklyn Nets", 2013, 0}}},
+	{"CHO", []Era{{"CHH", "Charlotte Hornets", 1989, 2002}, {"CHA", "Charlotte Bobcats", 2005, 2014}, {"CHO", "Charlotte Hornets", 2015, 0}}},
+	{"CHI", []Era{{"CHI", "Chicago Bulls", 1967, 0}}},
+	{"CLE", []Era{{"CLE", "Cleveland Cavaliers", 1971, 0}}},
+	{"DAL", []Era{{"DAL", "Dallas Mavericks", 1981, 0}}},
+	{"DEN", []Era{{"DNR", "Denver Rockets", 1968, 1974}, {"DEN", "Denver Nuggets", 1975, 0}}},
+	{"DET", []Era{{"FTW", "Fort Wayne Pistons", 1949, 1957}, {"DET", "Detroit Pistons", 1958, 0}}},
+	{"GSW", []Era{{"PHW", "Philadelphia Warriors", 1947, 1962}, {"SFW", "San Francisco Warriors", 1963, 1971}, {"GSW", "Golden State Warriors", 1972, 0}}},
+	{"HOU", []Era{{"SDR", "San Diego Rockets", 1968, 1971}, {"HOU", "Houston Rockets", 1972, 0}}},
+	{"IND", []Era{{"INA", "Indiana Pacers", 1968, 1976}, {"IND", "Indiana Pacers", 1977, 0}}},
+	{"LAC", []Era{{"BUF", "Buffalo Braves", 1971, 1978}, {"SDC", "San Diego Clippers", 1979, 1984}, {"LAC", "Los Angeles Clippers", 1985, 0}}},
+	{"LAL", []Era{{"MNL", "Minneapolis Lakers", 1949, 1960}, {"LAL", "Los Angeles Lakers", 1961, 0}}},
+	{"MEM", []Era{{"VAN", "Vancouver Grizzlies", 1996, 2001}, {"MEM", "Memphis Grizzlies", 2002, 0}}},
+	{"MIA", []Era{{"MIA", "Miami Heat", 1989, 0}}},
+	{"MIL", []Era{{"MIL", "Milwaukee Bucks", 1969, 0}}},
+	{"MIN", []Era{{"MIN", "Minnesota Timberwolves", 1990, 0}}},
+	{"NOP", []Era{{"NOH", "New Orleans Hornets", 2003, 2005}, {"NOK", "New Orleans/Oklahoma City Hornets", 2006, 2007}, {"NOH", "New Orleans Hornets", 2008, 2013}, {"NOP", "New Orleans Pelicans", 2014, 0}}},
+	{"NYK", []Era{{"NYK", "New York Knicks", 1947, 0}}},
+	{"OKC", []Era{{"SEA", "Seattle SuperSonics", 1968, 2008}, {"OKC", "Oklahoma City Thunder", 2009, 0}}},
+	{"ORL", []Era{{"ORL", "Orlando Magic", 1990, 0}}},
+	{"PHI", []Era{{"SYR", "Syracuse Nationals", 1950, 1963}, {"PHI", "Philadelphia 76ers", 1964, 0}}},
+	{"PHO", []Era{{"PHO", "Phoenix Suns", 1969, 0}}},
+	{"POR", []Era{{"POR", "Portland Trail Blazers", 1971, 0}}},
+	{"SAC", []Era{{"ROC", "Rochester Royals", 1949, 1957}, {"CIN", "Cincinnati Royals", 1958, 1972}, {"KCO", "Kansas City-Omaha Kings", 1973, 1975}, {"KCK", "Kansas City Kings", 1976, 1985}, {"SAC", "Sacramento Kings", 1986, 0}}},
+	{"SAS", []Era{{"DLC", "Dallas Chaparrals", 1968, 1973}, {"SAA", "San Antonio Spurs", 1974, 1976}, {"SAS", "San Antonio Spurs", 1977, 0}}},
+	{"TOR", []Era{{"TOR", "Toronto Raptors", 1996, 0}}},
+	{"UTA", []Era{{"NOJ", "New Orleans Jazz", 1975, 1979}, {"UTA", "Utah Jazz", 1980, 0}}},
+	{"WAS", []Era{{"CHP", "Chicago Packers", 1962, 1962}, {"CHZ", "Chicago Zephyrs", 1963, 1963}, {"BAL", "Baltimore Bullets", 1964, 1973}, {"CAP", "Capital Bullets", 1974, 1974}, {"WSB", "Washington Bullets", 1975, 1997}, {"WAS", "Washington Wizards", 1998, 0}}},
+}
+
+// aliases are codes other sites use for current teams.
+var aliases = map[string]string{
+	"BKN": "BRK", "GS": "GSW", "NJ": "BRK", "NO": "NOP", "NOR": "NOP",
+	"NY": "NYK", "PHX": "PHO", "SA": "SAS", "UTAH": "UTA", "WSH": "WAS",
+}
+
+var byCode = func() map[string]int {
+	m := make(map[string]int)
+	for i, f := range franchises {
+		for _, e := range f.Eras {
+			m[e.Code] = i
+		}
+	}
+	return m
+}()
+
+// Lookup finds the franchise that used code at any point, accepting common
+// aliases such as BKN and PHX. CHH resolves to the current Hornets, which
+// basketball-reference credits with the 1989-2002 Charlotte history.
+func Lookup(code string) (Franchise, bool) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if a, ok := aliases[code]; ok {
+		code = a
+	}
+	i, ok := byCode[code]
+	if !ok {
+		return Franchise{}, false
+	}
+	return franchises[i], true
+}
+
+// All returns every franchise.
+func All() []Franchise {
+	return franchises
+}
+
+// EraFor returns the code and name the franchise used in the season ending
+// in year. ok is false if the franchise did not play that season.
+func (f Franchise) EraFor(year int) (Era, bool) {
+	for _, e := range f.Eras {
+		if year >= e.From && (e.To == 0 || year <= e.To) {
+			return e, true
+		}
+	}
+	return Era{}, false
+}
+
+// Codes returns every code the franchise has used, without repeats.
+func (f Franchise) Codes() []string {
+	var codes []string
+	seen := make(map[string]bool)
+	for _, e := range f.Eras {
+		if !seen[e.Code] {
+			seen[e.Code] = true
+			codes = append(codes, e.Code)
+		}
+	}
+	return codes
+}
+
+// Conferences.
+const (
+	East = "East"
+	West = "West"
+)
+
+// FirstAlignedSeason is the first season, by end year, of the current
+// six-division alignment, the earliest Alignment knows.
+const FirstAlignedSeason = 2005
+
+// divisions places each franchise since the 2004-05 realignment.
+var divisions = map[string][2]string{
+	"BOS": {East, "Atlantic"}, "BRK": {East, "Atlantic"}, "NYK": {East, "Atlantic"}, "PHI": {East, "Atlantic"}, "TOR": {East, "Atlantic"},
+	"CHI": {East, "Central"}, "CLE": {East, "Central"}, "DET": {East, "Central"}, "IND": {East, "Central"}, "MIL": {East, "Central"},
+	"ATL": {East, "Southeast"}, "CHO": {East, "Southeast"}, "MIA": {East, "Southeast"}, "ORL": {East, "Southeast"}, "WAS": {East, "Southeast"},
+	"DEN": {West, "Northwest"}, "MIN": {West, "Northwest"}, "OKC": {West, "Northwest"}, "POR": {West, "Northwest"}, "UTA": {West, "Northwest"},
+	"GSW": {West, "Pacific"}, "LAC": {West, "Pacific"}, "LAL": {West, "Pacific"}, "PHO": {West, "Pacific"}, "SAC": {West, "Pacific"},
+	"DAL": {West, "Southwest"}, "HOU": {West, "Southwest"}, "MEM": {West, "Southwest"}, "NOP": {West, "Southwest"}, "SAS": {West, "Southwest"},
+}
+
+// Alignment returns the conference and division the franchise played in
+// during the season ending in year. ok is false before FirstAlignedSeason
+// or if the franchise did not play that season.
+func (f Franchise) Alignment(year int) (conference, division string, ok bool) {
+	if year < FirstAlignedSeason {
+		return "", "", false
+	}
+	if _, played := f.EraFor(year); !played {
+		return "", "", false
+	}
+	d := divisions[f.ID]
+	return d[0], d[1], true
+}
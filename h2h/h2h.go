@@ -0,0 +1,224 @@
+// Package h2h assembles the head-to-head history of two franchises from
+// stored results and box scores.
+package h2h
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/umanchanda/NBA-API/franchise"
+	"github.com/umanchanda/NBA-API/schedule"
+	"github.com/umanchanda/NBA-API/season"
+	"github.com/umanchanda/NBA-API/stats"
+	"github.com/umanchanda/NBA-API/teamboxscore"
+	"github.com/umanchanda/NBA-API/warehouse"
+)
+
+// maxScrape caps how many box scores one request scrapes for meetings that
+// are not in the warehouse, so a request takes a few seconds at most. The
+// backfill command is the way to fill in the rest.
+const maxScrape = 3
+
+// Team identifies one side of the matchup.
+type Team struct {
+	Franchise string `json:"franchise"`
+	Name      string `json:"name"`
+}
+
+// Meeting is one game between the two teams.
+type Meeting struct {
+	GameID      string `json:"game_id"`
+	Date        string `json:"date"`
+	Season      string `json:"season"`
+	SeasonLabel string `json:"season_label"`
+	SeasonType  string `json:"season_type"`
+	Away        string `json:"away"`
+	Home        string `json:"home"`
+	AwayPts     int    `json:"away_pts"`
+	HomePts     int    `json:"home_pts"`
+	Winner      string `json:"winner"` // franchise id
+	BoxScore    string `json:"box_score"`
+}
+
+// Record counts each side's wins.
+type Record struct {
+	TeamA int `json:"team_a_wins"`
+	TeamB int `json:"team_b_wins"`
+}
+
+// SeasonRecord is the series record for one season, by season type.
+type SeasonRecord struct {
+	Season      string            `json:"season"`
+	SeasonLabel string            `json:"season_label"`
+	Records     map[string]Record `json:"records"`
+}
+
+// Stats aggregates each side's box score over the meetings it could find.
+// Missing counts meetings with no box score in the warehouse that were not
+// scraped, including any whose scrape failed.
+type Stats struct {
+	Games   int                  `json:"games"`
+	Missing int                  `json:"missing"`
+	Scraped int                  `json:"scraped"`
+	TeamA   *warehouse.Aggregate `json:"team_a"`
+	TeamB   *warehouse.Aggregate `json:"team_b"`
+}
+
+// HeadToHead is the /api/h2h/{teamA}/{teamB} response.
+type HeadToHead struct {
+	TeamA    Team              `json:"team_a"`
+	TeamB    Team              `json:"team_b"`
+	From     string            `json:"from,omitempty"`
+	To       string            `json:"to,omitempty"`
+	Records  map[string]Record `json:"records"`
+	Seasons  []SeasonRecord    `json:"seasons"`
+	Meetings []Meeting         `json:"meetings"`
+	Stats    Stats             `json:"stats"`
+}
+
+// Query describes a /api/h2h request.
+type Query struct {
+	TeamA, TeamB string
+	From, To     string // season keys, empty for no bound
+	Scrape       bool   // scrape box scores missing from the warehouse
+}
+
+// Between lists every meeting between two franchises under any of their
+// codes, oldest first, with the record by season type overall and per
+// season, and both teams' aggregate box scores over those games.
+func Between(db *sql.DB, q Query) (HeadToHead, error) {
+	a, ok := franchise.Lookup(q.TeamA)
+	if !ok {
+		return HeadToHead{}, stats.ErrBadQuery{Err: fmt.Errorf("unknown team %q", q.TeamA)}
+	}
+	b, ok := franchise.Lookup(q.TeamB)
+	if !ok {
+		return HeadToHead{}, stats.ErrBadQuery{Err: fmt.Errorf("unknown team %q", q.TeamB)}
+	}
+	if a.ID == b.ID {
+		return HeadToHead{}, stats.ErrBadQuery{Err: fmt.Errorf("%s and %s are the same franchise", q.TeamA, q.TeamB)}
+	}
+
+	results, err := schedule.Results(db, schedule.ResultQuery{
+		FromSeason: q.From,
+		ToSeason:   q.To,
+		Teams:      a.Codes(),
+	})
+	if err != nil {
+		return HeadToHead{}, err
+	}
+	side := func(code string) string {
+		if f, ok := franchise.Lookup(code); ok {
+			return f.ID
+		}
+		return ""
+	}
+
+	h := HeadToHead{
+		TeamA:    Team{a.ID, a.Eras[len(a.Eras)-1].Name},
+		TeamB:    Team{b.ID, b.Eras[len(b.Eras)-1].Name},
+		From:     q.From,
+		To:       q.To,
+		Records:  make(map[string]Record),
+		Seasons:  []SeasonRecord{},
+		Meetings: []Meeting{},
+		Stats:    Stats{TeamA: &warehouse.Aggregate{}, TeamB: &warehouse.Aggregate{}},
+	}
+	var met []schedule.Result
+	for _, r := range results {
+		away, home := side(r.Away), side(r.Home)
+		if !(away == a.ID && home == b.ID) && !(away == b.ID && home == a.ID) {
+			continue
+		}
+		met = append(met, r)
+
+		winner := side(r.Winner())
+		h.Meetings = append(h.Meetings, Meeting{
+			GameID:      r.GameID,
+			Date:        r.Date.Format("2006-01-02"),
+			Season:      r.Season,
+			SeasonLabel: season.Label(r.Season),
+			SeasonType:  r.SeasonType,
+			Away:        r.Away,
+			Home:        r.Home,
+			AwayPts:     r.AwayPts,
+			HomePts:     r.HomePts,
+			Winner:      winner,
+			BoxScore:    "/playerstats/" + r.Date.Format("2006/01/02") + "/" + r.Away + "/" + r.Home,
+		})
+
+		if n := len(h.Seasons); n == 0 || h.Seasons[n-1].Season != r.Season {
+			h.Seasons = append(h.Seasons, SeasonRecord{
+				Season:      r.Season,
+				SeasonLabel: season.Label(r.Season),
+				Records:     make(map[string]Record),
+			})
+		}
+		for _, records := range []map[string]Record{h.Records, h.Seasons[len(h.Seasons)-1].Records} {
+			rec := records[r.SeasonType]
+			if winner == a.ID {
+				rec.TeamA++
+			} else {
+				rec.TeamB++
+			}
+			records[r.SeasonType] = rec
+		}
+	}
+
+	if err := h.addStats(db, met, a.ID, q.Scrape, side); err != nil {
+		return HeadToHead{}, err
+	}
+	return h, nil
+}
+
+// addStats aggregates the box scores of the meetings, from the warehouse
+// and, if scrape is set, up to maxScrape scraped box scores, which are
+// stored so later requests find them.
+func (h *HeadToHead) addStats(db *sql.DB, met []schedule.Result, teamA string, scrape bool, side func(string) string) error {
+	ids := make([]string, len(met))
+	for i, r := range met {
+		ids[i] = r.GameID
+	}
+	lines, err := warehouse.TeamLines(db, ids)
+	if err != nil {
+		return err
+	}
+
+	tries := 0
+	for _, r := range met {
+		teams, ok := lines[r.GameID]
+		if !ok && scrape && tries < maxScrape {
+			if tries > 0 {
+				time.Sleep(time.Second)
+			}
+			tries++
+			g, err := warehouse.Scrape(r.Date, teamboxscore.TeamBoxScore{GameID: r.GameID, Status: "Final"})
+			if err != nil {
+				log.Printf("scraping head-to-head box score %s: %v", r.GameID, err)
+			} else {
+				teams, ok = g.Teams, true
+				h.Stats.Scraped++
+				if err := warehouse.Store(db, g); err != nil {
+					log.Printf("storing head-to-head box score %s: %v", r.GameID, err)
+				}
+			}
+		}
+		if !ok {
+			h.Stats.Missing++
+			continue
+		}
+		h.Stats.Games++
+		for _, t := range teams {
+			if side(t.Team) == teamA {
+				h.Stats.TeamA.Add(t.Line)
+			} else {
+				h.Stats.TeamB.Add(t.Line)
+			}
+		}
+	}
+	h.Stats.TeamA.Finish()
+	h.Stats.TeamB.Finish()
+	return nil
+}
@@ -0,0 +1,23 @@
+// Package db opens the Postgres database shared by the server and the
+// commands.
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+
+	_ "github.com/lib/pq"
+)
+
+// Open connects to DATABASE_URL, or to the database described by DB_USER,
+// DB_PASSWORD, DB_HOST, DB_PORT and DB_NAME when it is not set.
+func Open() (*sql.DB, error) {
+	connStr := os.Getenv("DATABASE_URL")
+	if connStr == "" {
+		connStr = fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=require",
+			os.Getenv("DB_USER"), os.Getenv("DB_PASSWORD"),
+			os.Getenv("DB_HOST"), os.Getenv("DB_PORT"), os.Getenv("DB_NAME"))
+	}
+	return sql.Open("postgres", connStr)
+}
@@ -1,12 +1,19 @@
 package fetch
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 )
 
-// HTML fetches the given URL and returns the response body.
+// ErrNotFound is returned when the server answers 404, such as for a game
+// that was never played.
+var ErrNotFound = errors.New("page not found")
+
+// HTML fetches the given URL and returns the response body. A 404 is
+// ErrNotFound and any other status outside 2xx is an error, so error pages
+// are never parsed as content.
 func HTML(url string) ([]byte, error) {
 	resp, err := http.Get(url)
 	if err != nil {
@@ -14,6 +21,12 @@ func HTML(url string) ([]byte, error) {
 	}
 	defer resp.Body.Close()
 
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, fmt.Errorf("%s: %w", url, ErrNotFound)
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		return nil, fmt.Errorf("fetching %s: HTTP %d", url, resp.StatusCode)
+	}
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, fmt.Errorf("reading response from %s: %w", url, err)
@@ -0,0 +1,37 @@
+package fetch
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHTMLStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ok":
+			w.Write([]byte("<html>ok</html>"))
+		case "/busy":
+			http.Error(w, "<html>slow down</html>", http.StatusTooManyRequests)
+		case "/down":
+			http.Error(w, "<html>oops</html>", http.StatusBadGateway)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	if body, err := HTML(srv.URL + "/ok"); err != nil || string(body) != "<html>ok</html>" {
+		t.Errorf("200: %q, %v", body, err)
+	}
+	if _, err := HTML(srv.URL + "/missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("404: %v, want ErrNotFound", err)
+	}
+	for _, path := range []string{"/busy", "/down"} {
+		body, err := HTML(srv.URL + path)
+		if err == nil || errors.Is(err, ErrNotFound) || body != nil {
+			t.Errorf("%s: %q, %v, want an error other than ErrNotFound", path, body, err)
+		}
+	}
+}
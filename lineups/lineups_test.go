@@ -0,0 +1,125 @@
+package lineups
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/umanchanda/NBA-API/pbp"
+)
+
+// game is a hand-built BOS @ NYK play-by-play. In the 2nd quarter a1 is
+// subbed out at 12:00 before doing anything, and a3-a5 and every NYK player
+// never show up; the 3rd and 4th quarters have no plays at all.
+func game() pbp.PlayByPlay {
+	yes := true
+	away := func(clock string, period int, typ, p1, p2 string) pbp.Event {
+		return pbp.Event{Period: period, Clock: clock, Team: "BOS", Type: typ, Player: p1, Player2: p2}
+	}
+	home := func(clock string, period int, typ, p1, p2 string) pbp.Event {
+		e := away(clock, period, typ, p1, p2)
+		e.Team, e.Home = "NYK", true
+		return e
+	}
+	edge := func(clock string, period int, typ string) pbp.Event {
+		return pbp.Event{Period: period, Clock: clock, Type: typ}
+	}
+
+	events := []pbp.Event{
+		edge("12:00", 1, pbp.TypePeriodStart),
+		away("11:00", 1, pbp.TypeShot, "a1", "a2"),
+		home("10:30", 1, pbp.TypeShot, "h1", "h2"),
+		away("10:00", 1, pbp.TypeRebound, "a3", ""),
+		away("9:00", 1, pbp.TypeFoul, "a4", "h3"),
+		away("8:00", 1, pbp.TypeTurnover, "a5", "h4"),
+		home("7:00", 1, pbp.TypeRebound, "h5", ""),
+		edge("0:00", 1, pbp.TypePeriodEnd),
+		edge("12:00", 2, pbp.TypePeriodStart),
+		away("12:00", 2, pbp.TypeSub, "a6", "a1"),
+		away("6:00", 2, pbp.TypeShot, "a2", ""),
+		edge("0:00", 2, pbp.TypePeriodEnd),
+		edge("12:00", 3, pbp.TypePeriodStart),
+		edge("0:00", 3, pbp.TypePeriodEnd),
+		edge("12:00", 4, pbp.TypePeriodStart),
+		edge("0:00", 4, pbp.TypePeriodEnd),
+	}
+	for i := range events {
+		e := &events[i]
+		e.Seq = i + 1
+		m, s, _ := strings.Cut(e.Clock, ":")
+		mins, _ := strconv.Atoi(m)
+		secs, _ := strconv.Atoi(s)
+		e.Remaining = float64(60*mins + secs)
+		if e.Type == pbp.TypeShot {
+			e.Made, e.Points = &yes, 2
+		}
+	}
+	return pbp.PlayByPlay{GameID: "202403110NYK", Away: "BOS", Home: "NYK", Events: events}
+}
+
+func TestTrackStarters(t *testing.T) {
+	p := game()
+	floors := Track(p)
+	tests := []struct {
+		event      int // index into p.Events
+		away, home []string
+	}{
+		{1, []string{"a1", "a2", "a3", "a4", "a5"}, []string{"h1", "h2", "h3", "h4", "h5"}},
+		// The sub at the start of the 2nd: a1 started the period and a3-a5
+		// carry over from the 1st without appearing.
+		{9, []string{"a1", "a2", "a3", "a4", "a5"}, []string{"h1", "h2", "h3", "h4", "h5"}},
+		{10, []string{"a2", "a3", "a4", "a5", "a6"}, []string{"h1", "h2", "h3", "h4", "h5"}},
+		// Empty periods keep the last five on the floor.
+		{14, []string{"a2", "a3", "a4", "a5", "a6"}, []string{"h1", "h2", "h3", "h4", "h5"}},
+	}
+	for _, tt := range tests {
+		f := floors[tt.event]
+		if !reflect.DeepEqual(f.Away, tt.away) || !reflect.DeepEqual(f.Home, tt.home) {
+			t.Errorf("event %d (%s): floor = %v / %v, want %v / %v",
+				tt.event, p.Events[tt.event].Type, f.Away, f.Home, tt.away, tt.home)
+		}
+	}
+}
+
+func TestBookSeconds(t *testing.T) {
+	b := NewBook()
+	b.Add(game())
+
+	perTeam := make(map[string]float64)
+	for key, c := range b.on {
+		team, _, _ := strings.Cut(key, "|")
+		perTeam[team] += c.seconds
+	}
+	for _, team := range []string{"BOS", "NYK"} {
+		if perTeam[team] != 48*60*5 {
+			t.Errorf("%s players played %v seconds, want %v", team, perTeam[team], 48*60*5)
+		}
+	}
+
+	want := map[string]float64{"BOS|a1": 720, "BOS|a2": 2880, "BOS|a6": 2160, "NYK|h1": 2880}
+	for key, secs := range want {
+		if got := b.on[key].seconds; got != secs {
+			t.Errorf("%s played %v seconds, want %v", key, got, secs)
+		}
+	}
+
+	fives := make(map[string]float64)
+	for key, c := range b.fives {
+		team, _, _ := strings.Cut(key, "|")
+		fives[team] += c.seconds
+	}
+	for _, team := range []string{"BOS", "NYK"} {
+		if fives[team] != 48*60 {
+			t.Errorf("%s lineups played %v seconds, want %v", team, fives[team], 48*60)
+		}
+	}
+
+	r := b.Report("BOS", 0)
+	if len(r.Lineups) != 2 || r.Lineups[0].Minutes != 36 || r.Lineups[1].Minutes != 12 {
+		t.Errorf("BOS lineups = %+v, want 36 and 12 minute units", r.Lineups)
+	}
+	if r.Lineups[0].PointsFor != 2 || r.Lineups[1].PointsFor != 2 || r.Lineups[1].PointsAgainst != 2 {
+		t.Errorf("BOS lineup points = %+v", r.Lineups)
+	}
+}
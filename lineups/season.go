@@ -0,0 +1,76 @@
+package lineups
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+
+	"github.com/umanchanda/NBA-API/franchise"
+	"github.com/umanchanda/NBA-API/pbp"
+	"github.com/umanchanda/NBA-API/schedule"
+	"github.com/umanchanda/NBA-API/season"
+	"github.com/umanchanda/NBA-API/stats"
+)
+
+// TeamReport is the /api/team/{code}/lineups response. Missing counts the
+// team's games with no stored play-by-play.
+type TeamReport struct {
+	Franchise   string `json:"franchise"`
+	Team        string `json:"team"`
+	Name        string `json:"name"`
+	Season      string `json:"season"`
+	SeasonLabel string `json:"season_label"`
+	SeasonType  string `json:"season_type"`
+	Missing     int    `json:"missing"`
+	Report
+}
+
+// ForTeam aggregates a team's lineups over a season's stored play-by-play,
+// keeping units that played at least minMinutes.
+func ForTeam(db *sql.DB, code, seasonKey, seasonType string, minMinutes float64) (TeamReport, error) {
+	f, ok := franchise.Lookup(code)
+	if !ok {
+		return TeamReport{}, stats.ErrBadQuery{Err: fmt.Errorf("unknown team %q", code)}
+	}
+	year, _ := strconv.Atoi(seasonKey)
+	era, ok := f.EraFor(year)
+	if !ok {
+		return TeamReport{}, stats.ErrBadQuery{Err: fmt.Errorf("%s did not play in %s", f.ID, season.Label(seasonKey))}
+	}
+	if seasonType == "" {
+		seasonType = stats.SeasonTypeRegular
+	}
+
+	results, err := schedule.Results(db, schedule.ResultQuery{
+		FromSeason: seasonKey,
+		ToSeason:   seasonKey,
+		SeasonType: seasonType,
+		Teams:      []string{era.Code},
+	})
+	if err != nil {
+		return TeamReport{}, err
+	}
+	ids := make([]string, len(results))
+	for i, r := range results {
+		ids[i] = r.GameID
+	}
+	games, err := pbp.LoadGames(db, ids)
+	if err != nil {
+		return TeamReport{}, err
+	}
+
+	b := NewBook()
+	for _, g := range games {
+		b.Add(g)
+	}
+	return TeamReport{
+		Franchise:   f.ID,
+		Team:        era.Code,
+		Name:        era.Name,
+		Season:      seasonKey,
+		SeasonLabel: season.Label(seasonKey),
+		SeasonType:  seasonType,
+		Missing:     len(ids) - len(games),
+		Report:      b.Report(era.Code, minMinutes),
+	}, nil
+}
@@ -0,0 +1,272 @@
+package lineups
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/umanchanda/NBA-API/pbp"
+)
+
+// counts accumulates one unit's minutes and both teams' scoring and
+// possession-ending plays while it is on the floor.
+type counts struct {
+	seconds float64
+	pf, pa  int
+	// Offense and defense: field goal attempts, offensive rebounds,
+	// turnovers and free throw attempts.
+	fga, orb, tov, fta     int
+	oFGA, oORB, oTOV, oFTA int
+}
+
+func (c *counts) add(o *counts) {
+	c.seconds += o.seconds
+	c.pf, c.pa = c.pf+o.pf, c.pa+o.pa
+	c.fga, c.orb, c.tov, c.fta = c.fga+o.fga, c.orb+o.orb, c.tov+o.tov, c.fta+o.fta
+	c.oFGA, c.oORB, c.oTOV, c.oFTA = c.oFGA+o.oFGA, c.oORB+o.oORB, c.oTOV+o.oTOV, c.oFTA+o.oFTA
+}
+
+func (c *counts) sub(o *counts) {
+	c.seconds -= o.seconds
+	c.pf, c.pa = c.pf-o.pf, c.pa-o.pa
+	c.fga, c.orb, c.tov, c.fta = c.fga-o.fga, c.orb-o.orb, c.tov-o.tov, c.fta-o.fta
+	c.oFGA, c.oORB, c.oTOV, c.oFTA = c.oFGA-o.oFGA, c.oORB-o.oORB, c.oTOV-o.oTOV, c.oFTA-o.oFTA
+}
+
+// event counts a play by the unit's team (offense) or its opponent.
+func (c *counts) event(e pbp.Event, offense bool) {
+	fga, orb, tov, fta, pts := &c.oFGA, &c.oORB, &c.oTOV, &c.oFTA, &c.pa
+	if offense {
+		fga, orb, tov, fta, pts = &c.fga, &c.orb, &c.tov, &c.fta, &c.pf
+	}
+	*pts += e.Points
+	switch e.Type {
+	case pbp.TypeShot:
+		*fga++
+	case pbp.TypeFreeThrow:
+		*fta++
+	case pbp.TypeTurnover:
+		*tov++
+	case pbp.TypeRebound:
+		if e.Detail == "offensive" {
+			*orb++
+		}
+	}
+}
+
+// Line is a unit's results: minutes, points for and against, estimated
+// possessions (FGA - ORB + TOV + 0.44 FTA) and points per 100 possessions.
+type Line struct {
+	Minutes       float64  `json:"minutes"`
+	PointsFor     int      `json:"points_for"`
+	PointsAgainst int      `json:"points_against"`
+	PlusMinus     int      `json:"plus_minus"`
+	PossFor       float64  `json:"poss_for"`
+	PossAgainst   float64  `json:"poss_against"`
+	ORtg          *float64 `json:"ortg"`
+	DRtg          *float64 `json:"drtg"`
+	NetRtg        *float64 `json:"net_rtg"`
+}
+
+func round(v float64, places int) float64 {
+	p := math.Pow(10, float64(places))
+	return math.Round(v*p) / p
+}
+
+func (c *counts) line() Line {
+	possFor := float64(c.fga-c.orb+c.tov) + 0.44*float64(c.fta)
+	possAgainst := float64(c.oFGA-c.oORB+c.oTOV) + 0.44*float64(c.oFTA)
+	l := Line{
+		Minutes:       round(c.seconds/60, 1),
+		PointsFor:     c.pf,
+		PointsAgainst: c.pa,
+		PlusMinus:     c.pf - c.pa,
+		PossFor:       round(possFor, 1),
+		PossAgainst:   round(possAgainst, 1),
+	}
+	if possFor > 0 {
+		v := round(100*float64(c.pf)/possFor, 1)
+		l.ORtg = &v
+	}
+	if possAgainst > 0 {
+		v := round(100*float64(c.pa)/possAgainst, 1)
+		l.DRtg = &v
+	}
+	if l.ORtg != nil && l.DRtg != nil {
+		v := round(*l.ORtg-*l.DRtg, 1)
+		l.NetRtg = &v
+	}
+	return l
+}
+
+// Unit is a five-man lineup or two-man combination.
+type Unit struct {
+	Team    string   `json:"team"`
+	Players []string `json:"players"`
+	Line
+}
+
+// OnOff is a team's results with a player on the floor and off it, over
+// the games the player got into.
+type OnOff struct {
+	Team    string   `json:"team"`
+	Player  string   `json:"player"`
+	On      Line     `json:"on"`
+	Off     Line     `json:"off"`
+	NetDiff *float64 `json:"net_diff"` // on net rating minus off
+}
+
+// Report is the lineups response for a game or a team's season.
+type Report struct {
+	Games   int     `json:"games"`
+	Lineups []Unit  `json:"lineups"`
+	Pairs   []Unit  `json:"pairs"`
+	OnOff   []OnOff `json:"on_off"`
+}
+
+// Book accumulates lineup results over games. Teams are identified by the
+// codes in the play-by-play.
+type Book struct {
+	games  int
+	fives  map[string]*counts // by team + "|" + sorted ids
+	pairs  map[string]*counts
+	on     map[string]*counts // by team + "|" + player
+	played map[string]*counts // the player's team's totals in games they played
+}
+
+// NewBook returns an empty book.
+func NewBook() *Book {
+	return &Book{
+		fives:  make(map[string]*counts),
+		pairs:  make(map[string]*counts),
+		on:     make(map[string]*counts),
+		played: make(map[string]*counts),
+	}
+}
+
+func bump(m map[string]*counts, key string) *counts {
+	c := m[key]
+	if c == nil {
+		c = &counts{}
+		m[key] = c
+	}
+	return c
+}
+
+// Add plays a game through the book.
+func (b *Book) Add(p pbp.PlayByPlay) {
+	if len(p.Events) == 0 {
+		return
+	}
+	b.games++
+	floors := Track(p)
+	teams := map[bool]string{false: p.Away, true: p.Home}
+	totals := map[bool]*counts{false: {}, true: {}}
+	on := make(map[string]*counts) // this game only, by team + "|" + player
+
+	// units returns every counts the side's floor feeds.
+	units := func(f Floor, home bool) []*counts {
+		five := f.Away
+		if home {
+			five = f.Home
+		}
+		team := teams[home]
+		out := []*counts{totals[home]}
+		if len(five) == 5 {
+			out = append(out, bump(b.fives, team+"|"+strings.Join(five, ",")))
+		}
+		for i, a := range five {
+			out = append(out, bump(on, team+"|"+a))
+			for _, c := range five[i+1:] {
+				out = append(out, bump(b.pairs, team+"|"+a+","+c))
+			}
+		}
+		return out
+	}
+
+	prev := 0.0
+	for i, e := range p.Events {
+		now := e.Elapsed()
+		if i > 0 {
+			// The time since the last play was played by the floor in place
+			// for this one.
+			dt := now - prev
+			after := floors[i]
+			for _, home := range []bool{false, true} {
+				for _, c := range units(after, home) {
+					c.seconds += dt
+				}
+			}
+		}
+		prev = now
+		if e.Team == "" {
+			continue
+		}
+		for _, home := range []bool{false, true} {
+			for _, c := range units(floors[i], home) {
+				c.event(e, e.Home == home)
+			}
+		}
+	}
+
+	for key, c := range on {
+		bump(b.on, key).add(c)
+		team, _, _ := strings.Cut(key, "|")
+		bump(b.played, key).add(totals[team == p.Home])
+	}
+}
+
+// Report returns every unit of team, or of both teams when team is empty,
+// that played at least minMinutes, most minutes first.
+func (b *Book) Report(team string, minMinutes float64) Report {
+	r := Report{Games: b.games, Lineups: []Unit{}, Pairs: []Unit{}, OnOff: []OnOff{}}
+	split := func(key string) (string, string) {
+		t, rest, _ := strings.Cut(key, "|")
+		return t, rest
+	}
+	keep := func(t string, c *counts) bool {
+		return (team == "" || t == team) && c.seconds >= minMinutes*60
+	}
+	for key, c := range b.fives {
+		if t, ids := split(key); keep(t, c) {
+			r.Lineups = append(r.Lineups, Unit{t, strings.Split(ids, ","), c.line()})
+		}
+	}
+	for key, c := range b.pairs {
+		if t, ids := split(key); keep(t, c) {
+			r.Pairs = append(r.Pairs, Unit{t, strings.Split(ids, ","), c.line()})
+		}
+	}
+	for key, c := range b.on {
+		t, player := split(key)
+		if !keep(t, c) {
+			continue
+		}
+		off := *b.played[key]
+		off.sub(c)
+		o := OnOff{Team: t, Player: player, On: c.line(), Off: off.line()}
+		if o.On.NetRtg != nil && o.Off.NetRtg != nil {
+			v := round(*o.On.NetRtg-*o.Off.NetRtg, 1)
+			o.NetDiff = &v
+		}
+		r.OnOff = append(r.OnOff, o)
+	}
+
+	byMinutes := func(units []Unit) {
+		sort.Slice(units, func(i, j int) bool {
+			if units[i].Minutes != units[j].Minutes {
+				return units[i].Minutes > units[j].Minutes
+			}
+			return strings.Join(units[i].Players, ",") < strings.Join(units[j].Players, ",")
+		})
+	}
+	byMinutes(r.Lineups)
+	byMinutes(r.Pairs)
+	sort.Slice(r.OnOff, func(i, j int) bool {
+		if r.OnOff[i].On.Minutes != r.OnOff[j].On.Minutes {
+			return r.OnOff[i].On.Minutes > r.OnOff[j].On.Minutes
+		}
+		return r.OnOff[i].Player < r.OnOff[j].Player
+	})
+	return r
+}
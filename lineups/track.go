@@ -0,0 +1,159 @@
+// Package lineups follows the five players each team has on the floor
+// through a game's play-by-play and rates five-man lineups, two-man
+// combinations and each player's minutes on and off the floor.
+package lineups
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/umanchanda/NBA-API/pbp"
+)
+
+// Floor is who was on the floor for each team when an event happened,
+// sorted by player id. For a substitution it is the floor before the swap.
+type Floor struct {
+	Away []string
+	Home []string
+}
+
+// involvement is a player taking part in an event for a team.
+type involvement struct {
+	player string
+	home   bool
+}
+
+// involved returns the players an event shows to be on the floor. Players
+// credited with technical fouls are left out, since they can be on the
+// bench, and so are jump ball players, whose side isn't listed.
+func involved(e pbp.Event) []involvement {
+	if e.Team == "" {
+		return nil
+	}
+	own := func(id string) []involvement {
+		if id == "" {
+			return nil
+		}
+		return []involvement{{id, e.Home}}
+	}
+	opp := func(id string) []involvement {
+		if id == "" {
+			return nil
+		}
+		return []involvement{{id, !e.Home}}
+	}
+	switch e.Type {
+	case pbp.TypeShot:
+		if e.Made != nil && *e.Made {
+			return append(own(e.Player), own(e.Player2)...)
+		}
+		return append(own(e.Player), opp(e.Player2)...)
+	case pbp.TypeTurnover:
+		return append(own(e.Player), opp(e.Player2)...)
+	case pbp.TypeFoul:
+		if strings.Contains(e.Detail, "tech") {
+			return nil
+		}
+		return append(own(e.Player), opp(e.Player2)...)
+	case pbp.TypeFreeThrow, pbp.TypeRebound, pbp.TypeViolation:
+		return own(e.Player)
+	case pbp.TypeSub:
+		return append(own(e.Player), own(e.Player2)...)
+	}
+	return nil
+}
+
+// starters infers who started a period for one side: everyone who does
+// something in the period before being subbed in, or who is subbed out
+// first. Players who finished the previous period and aren't subbed in
+// fill a lineup with fewer than five, since a player can go a whole period
+// without showing up in the play-by-play.
+func starters(events []pbp.Event, home bool, previous []string) []string {
+	var five []string
+	seen := make(map[string]bool)
+	for _, e := range events {
+		if e.Type == pbp.TypeSub {
+			if e.Home != home {
+				continue
+			}
+			if out := e.Player2; out != "" && !seen[out] {
+				five = append(five, out)
+				seen[out] = true
+			}
+			seen[e.Player] = true
+			continue
+		}
+		for _, in := range involved(e) {
+			if in.home == home && !seen[in.player] {
+				five = append(five, in.player)
+				seen[in.player] = true
+			}
+		}
+	}
+	for _, id := range previous {
+		if len(five) >= 5 {
+			break
+		}
+		if !seen[id] {
+			five = append(five, id)
+			seen[id] = true
+		}
+	}
+	if len(five) > 5 {
+		five = five[:5]
+	}
+	return five
+}
+
+// Track returns the floor at every event of a game.
+func Track(p pbp.PlayByPlay) []Floor {
+	floors := make([]Floor, len(p.Events))
+	var away, home []string
+	for start := 0; start < len(p.Events); {
+		end := start
+		for end < len(p.Events) && p.Events[end].Period == p.Events[start].Period {
+			end++
+		}
+		period := p.Events[start:end]
+		away = starters(period, false, away)
+		home = starters(period, true, home)
+
+		for i, e := range period {
+			floors[start+i] = Floor{Away: sorted(away), Home: sorted(home)}
+			if e.Type != pbp.TypeSub {
+				continue
+			}
+			if e.Home {
+				home = swap(home, e.Player2, e.Player)
+			} else {
+				away = swap(away, e.Player2, e.Player)
+			}
+		}
+		start = end
+	}
+	return floors
+}
+
+// swap replaces out with in, or adds in if out wasn't tracked on the floor
+// and there is room.
+func swap(five []string, out, in string) []string {
+	next := make([]string, 0, 5)
+	for _, id := range five {
+		if id == in {
+			return five
+		}
+		if id != out {
+			next = append(next, id)
+		}
+	}
+	if len(next) < 5 {
+		next = append(next, in)
+	}
+	return next
+}
+
+func sorted(ids []string) []string {
+	out := append([]string(nil), ids...)
+	sort.Strings(out)
+	return out
+}
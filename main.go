@@ -2,69 +2,172 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
-	_ "github.com/lib/pq"
 
+	"github.com/umanchanda/NBA-API/ask"
+	"github.com/umanchanda/NBA-API/clutch"
 	"github.com/umanchanda/NBA-API/espn"
+	"github.com/umanchanda/NBA-API/filter"
+	"github.com/umanchanda/NBA-API/franchise"
+	"github.com/umanchanda/NBA-API/h2h"
+	"github.com/umanchanda/NBA-API/internal/db"
+	"github.com/umanchanda/NBA-API/internal/fetch"
+	"github.com/umanchanda/NBA-API/lineups"
+	"github.com/umanchanda/NBA-API/pbp"
+	"github.com/umanchanda/NBA-API/players"
 	"github.com/umanchanda/NBA-API/playertotals"
+	"github.com/umanchanda/NBA-API/playoffs"
+	"github.com/umanchanda/NBA-API/ratings"
+	"github.com/umanchanda/NBA-API/schedule"
+	"github.com/umanchanda/NBA-API/season"
+	"github.com/umanchanda/NBA-API/similar"
+	"github.com/umanchanda/NBA-API/simulate"
+	"github.com/umanchanda/NBA-API/standings"
+	"github.com/umanchanda/NBA-API/stats"
 	"github.com/umanchanda/NBA-API/teamboxscore"
 	"github.com/umanchanda/NBA-API/teamtotals"
+	"github.com/umanchanda/NBA-API/warehouse"
+	"github.com/umanchanda/NBA-API/winprob"
 
 	"database/sql"
 )
 
-func dbConn() (*sql.DB, error) {
-	connStr := os.Getenv("DATABASE_URL")
-	if connStr == "" {
-		connStr = fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=require",
-			os.Getenv("DB_USER"), os.Getenv("DB_PASSWORD"),
-			os.Getenv("DB_HOST"), os.Getenv("DB_PORT"), os.Getenv("DB_NAME"))
+// intParam reads an optional integer query parameter, returning def when it
+// is absent and an error when it is malformed or outside [lo, hi].
+func intParam(r *http.Request, name string, def, lo, hi int) (int, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return def, nil
 	}
-	return sql.Open("postgres", connStr)
+	n, err := strconv.Atoi(v)
+	if err != nil || n < lo || n > hi {
+		return 0, fmt.Errorf("%s must be between %d and %d", name, lo, hi)
+	}
+	return n, nil
+}
+
+// seasonParam reads an optional season query parameter in any form
+// season.Parse accepts and returns the key seasons are stored under.
+func seasonParam(r *http.Request, name string) (string, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return "", nil
+	}
+	s, err := season.Parse(v)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", name, err)
+	}
+	return s.Key(), nil
+}
+
+// dateVars validates the {year}/{month}/{day} route variables and returns
+// them zero-padded, the way basketball-reference URLs expect.
+func dateVars(r *http.Request) (year, month, day string, err error) {
+	vars := mux.Vars(r)
+	t, err := season.ParseDate(vars["year"], vars["month"], vars["day"])
+	if err != nil {
+		return "", "", "", err
+	}
+	return t.Format("2006"), t.Format("01"), t.Format("02"), nil
+}
+
+// writeJSON encodes v as the JSON response for route.
+func writeJSON(w http.ResponseWriter, route string, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("encoding %s response: %v", route, err)
+	}
+}
+
+// writeQueryError reports a failed stats query, as a 400 when the request
+// itself was at fault.
+func writeQueryError(w http.ResponseWriter, err error) {
+	var bad stats.ErrBadQuery
+	if errors.As(err, &bad) {
+		http.Error(w, bad.Error(), http.StatusBadRequest)
+		return
+	}
+	http.Error(w, err.Error(), http.StatusInternalServerError)
+}
+
+// stored reports whether a warehouse read succeeded, logging failures other
+// than the game not having been backfilled yet.
+func stored(route string, err error) bool {
+	if err != nil && !errors.Is(err, warehouse.ErrNotStored) && !errors.Is(err, pbp.ErrNotStored) {
+		log.Printf("reading %s from the warehouse: %v", route, err)
+	}
+	return err == nil
+}
+
+// playByPlay returns a game's play-by-play from pbp_events, scraping it
+// when it is not stored.
+func playByPlay(db *sql.DB, route, id string) (pbp.PlayByPlay, error) {
+	if p, err := pbp.Load(db, id); stored(route, err) {
+		return p, nil
+	}
+	return pbp.Scrape(id)
+}
+
+// canonicalGameID rewrites an alias home code in a game id, such as the
+// BKN in the box score page links, to basketball-reference's code for the
+// season.
+func canonicalGameID(id string) string {
+	date, err := time.Parse("20060102", id[:8])
+	if err != nil {
+		return id
+	}
+	f, ok := franchise.Lookup(id[9:])
+	if !ok {
+		return id
+	}
+	era, ok := f.EraFor(season.FromDate(date).End)
+	if !ok {
+		return id
+	}
+	return id[:9] + era.Code
 }
 
-type PlayerStat struct {
-	Season     string `json:"season"`
-	SeasonType string `json:"season_type"`
-	Name       string `json:"name"`
-	Team       string `json:"team"`
-	Pos        string `json:"pos"`
-	Age        string `json:"age"`
-	G          string `json:"g"`
-	GS         string `json:"gs"`
-	MP         string `json:"mp"`
-	FG         string `json:"fg"`
-	FGA        string `json:"fga"`
-	FGPct      string `json:"fg_pct"`
-	FG3        string `json:"fg3"`
-	FG3A       string `json:"fg3a"`
-	FG3Pct     string `json:"fg3_pct"`
-	FT         string `json:"ft"`
-	FTA        string `json:"fta"`
-	FTPct      string `json:"ft_pct"`
-	ORB        string `json:"orb"`
-	DRB        string `json:"drb"`
-	TRB        string `json:"trb"`
-	AST        string `json:"ast"`
-	STL        string `json:"stl"`
-	BLK        string `json:"blk"`
-	TOV        string `json:"tov"`
-	PF         string `json:"pf"`
-	PTS        string `json:"pts"`
+// writePlayByPlayError reports a failed play-by-play read, as a 404 when
+// basketball-reference has no page for the game.
+func writePlayByPlayError(w http.ResponseWriter, err error) {
+	if errors.Is(err, fetch.ErrNotFound) {
+		http.Error(w, "no play-by-play for this game", http.StatusNotFound)
+		return
+	}
+	http.Error(w, err.Error(), http.StatusInternalServerError)
 }
 
 func main() {
-	db, err := dbConn()
+	db, err := db.Open()
 	if err != nil {
 		log.Fatalf("connecting to database: %v", err)
 	}
 	defer db.Close()
 
+	// The warehouse tables are created up front so that, before anything
+	// is backfilled, reads find them empty and fall back to scraping.
+	for _, create := range []func(*sql.DB) error{warehouse.CreateTables, schedule.CreateTable, pbp.CreateTable} {
+		if err := create(db); err != nil {
+			log.Printf("creating warehouse tables: %v", err)
+		}
+	}
+
+	similarIndex := similar.NewStore(db)
+	playerIndex, err := players.NewStore(db, similarIndex.Rebuild)
+	if err != nil {
+		log.Printf("building player index: %v", err)
+	}
+	go playerIndex.Watch(time.Minute)
+
 	r := mux.NewRouter()
 
 	r.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
@@ -76,58 +179,553 @@ func main() {
 	})
 
 	r.HandleFunc("/api/player", func(w http.ResponseWriter, r *http.Request) {
-		name := r.URL.Query().Get("name")
-		season := r.URL.Query().Get("season")
-		seasonType := r.URL.Query().Get("season_type")
-		if name == "" {
-			http.Error(w, "name is required", http.StatusBadRequest)
+		params := r.URL.Query()
+		q := stats.PlayerQuery{
+			PlayerID:   params.Get("id"),
+			Name:       params.Get("name"),
+			SeasonType: params.Get("season_type"),
+			Cursor:     params.Get("cursor"),
+			Context:    params.Get("context") == "true",
+		}
+		var err error
+		if q.Season, err = seasonParam(r, "season"); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if expr := params.Get("q"); expr != "" {
+			f, err := filter.Parse(expr)
+			if err != nil {
+				http.Error(w, "q: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+			q.Filter = f
+		}
+		if q.PlayerID == "" && q.Name == "" && q.Filter == nil {
+			http.Error(w, "id, name or q is required", http.StatusBadRequest)
+			return
+		}
+
+		if q.Limit, err = intParam(r, "limit", stats.DefaultLimit, 1, stats.MaxLimit); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if q.Sort, err = stats.ParseSort(params.Get("sort")); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if q.Fields, err = stats.ParseFields(params.Get("fields")); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		page, err := stats.Players(db, q)
+		if err != nil {
+			writeQueryError(w, err)
 			return
 		}
 
-		query := `SELECT season, season_type, name, team, pos, age, g, gs, mp,
-			fg, fga, fg_pct, fg3, fg3a, fg3_pct,
-			ft, fta, ft_pct, orb, drb, trb,
-			ast, stl, blk, tov, pf, pts
-			FROM playerstats
-			WHERE LOWER(name) LIKE LOWER($1)`
-		args := []interface{}{"%" + name + "%"}
+		writeJSON(w, "/api/player", page)
+	})
 
-		if season != "" {
-			args = append(args, season)
-			query += fmt.Sprintf(" AND season = $%d", len(args))
+	r.HandleFunc("/api/player/search", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query().Get("q")
+		if q == "" {
+			http.Error(w, "q is required", http.StatusBadRequest)
+			return
+		}
+		limit, err := intParam(r, "limit", 10, 1, 100)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
 		}
-		if seasonType != "" {
-			args = append(args, seasonType)
-			query += fmt.Sprintf(" AND season_type = $%d", len(args))
+
+		matches := playerIndex.Index().Search(q, limit)
+		if matches == nil {
+			matches = []players.Match{}
 		}
-		query += " ORDER BY season DESC, season_type ASC, name ASC"
+		writeJSON(w, "/api/player/search", matches)
+	})
 
-		rows, err := db.Query(query, args...)
+	r.HandleFunc("/api/player/{id}/career", func(w http.ResponseWriter, r *http.Request) {
+		career, err := stats.PlayerCareer(db, mux.Vars(r)["id"])
+		if errors.Is(err, stats.ErrNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			writeQueryError(w, err)
+			return
+		}
+		writeJSON(w, "/api/player/{id}/career", career)
+	})
+
+	r.HandleFunc("/api/player/{id}/gamelog", func(w http.ResponseWriter, r *http.Request) {
+		seasonKey, err := seasonParam(r, "season")
 		if err != nil {
-			http.Error(w, "query failed: "+err.Error(), http.StatusInternalServerError)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		gl, err := stats.PlayerGameLog(db, mux.Vars(r)["id"], seasonKey, r.URL.Query().Get("season_type"))
+		if errors.Is(err, stats.ErrNotFound) || errors.Is(err, stats.ErrNoGameLogs) {
+			http.Error(w, err.Error(), http.StatusNotFound)
 			return
 		}
-		defer rows.Close()
+		if err != nil {
+			writeQueryError(w, err)
+			return
+		}
+		writeJSON(w, "/api/player/{id}/gamelog", gl)
+	})
+
+	r.HandleFunc("/api/player/{id}/similar", func(w http.ResponseWriter, r *http.Request) {
+		seasonKey, err := seasonParam(r, "season")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if seasonKey == "" {
+			http.Error(w, "season is required", http.StatusBadRequest)
+			return
+		}
+		k, err := intParam(r, "k", 10, 1, 100)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		result, err := similarIndex.Index().Similar(mux.Vars(r)["id"], seasonKey, k)
+		if errors.Is(err, similar.ErrNotIndexed) {
+			http.Error(w, "no regular season with enough minutes for this player and season", http.StatusNotFound)
+			return
+		}
+		writeJSON(w, "/api/player/{id}/similar", result)
+	})
+
+	r.HandleFunc("/api/players/suggest", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query().Get("q")
+		if q == "" {
+			http.Error(w, "q is required", http.StatusBadRequest)
+			return
+		}
+		limit, err := intParam(r, "limit", 8, 1, 50)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		suggestions := playerIndex.Index().Suggest(q, limit)
+		if suggestions == nil {
+			suggestions = []players.Player{}
+		}
+		writeJSON(w, "/api/players/suggest", suggestions)
+	})
+
+	r.HandleFunc("/api/leaders", func(w http.ResponseWriter, r *http.Request) {
+		params := r.URL.Query()
+		q := stats.LeaderQuery{
+			Stat:       params.Get("stat"),
+			SeasonType: params.Get("season_type"),
+			Per:        params.Get("per"),
+			Context:    params.Get("context") == "true",
+			RankBy:     params.Get("rank_by"),
+		}
+		if q.Stat == "" {
+			http.Error(w, "stat is required", http.StatusBadRequest)
+			return
+		}
+
+		var err error
+		if q.Season, err = seasonParam(r, "season"); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if q.MinG, err = intParam(r, "min_g", -1, 0, 82); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if q.Limit, err = intParam(r, "limit", 25, 1, stats.MaxLimit); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		board, err := stats.Leaders(db, q)
+		if err != nil {
+			writeQueryError(w, err)
+			return
+		}
+		writeJSON(w, "/api/leaders", board)
+	})
+
+	r.HandleFunc("/api/compare", func(w http.ResponseWriter, r *http.Request) {
+		params := r.URL.Query()
+		q := stats.CompareQuery{
+			SeasonType: params.Get("season_type"),
+			Mode:       params.Get("mode"),
+		}
+		var err error
+		if q.Season, err = seasonParam(r, "season"); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		for _, id := range strings.Split(params.Get("players"), ",") {
+			if id = strings.TrimSpace(id); id != "" {
+				q.PlayerIDs = append(q.PlayerIDs, id)
+			}
+		}
+		if len(q.PlayerIDs) > 10 {
+			http.Error(w, "at most 10 players can be compared", http.StatusBadRequest)
+			return
+		}
+
+		cmp, err := stats.Compare(db, q)
+		if errors.Is(err, stats.ErrNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			writeQueryError(w, err)
+			return
+		}
+		writeJSON(w, "/api/compare", cmp)
+	})
+
+	r.HandleFunc("/api/ask", func(w http.ResponseWriter, r *http.Request) {
+		text := r.URL.Query().Get("q")
+		if text == "" {
+			http.Error(w, "q is required", http.StatusBadRequest)
+			return
+		}
+		intent, err := ask.Parse(text, playerIndex.Index())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var result interface{}
+		if intent.Route == ask.RoutePlayer {
+			result, err = stats.Players(db, intent.PlayerQuery())
+		} else {
+			result, err = stats.Leaders(db, intent.LeaderQuery())
+		}
+		if err != nil {
+			writeQueryError(w, err)
+			return
+		}
+
+		writeJSON(w, "/api/ask", struct {
+			Query  string      `json:"query"`
+			Intent ask.Intent  `json:"intent"`
+			URL    string      `json:"url"`
+			Result interface{} `json:"result"`
+		}{text, intent, intent.URL(), result})
+	})
+
+	r.HandleFunc("/api/team/{code}/roster", func(w http.ResponseWriter, r *http.Request) {
+		params := r.URL.Query()
+		q := stats.RosterQuery{
+			Code:       mux.Vars(r)["code"],
+			SeasonType: params.Get("season_type"),
+			Franchise:  params.Get("franchise") == "true",
+		}
+		var err error
+		if q.Season, err = seasonParam(r, "season"); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		roster, err := stats.TeamRoster(db, q)
+		if err != nil {
+			writeQueryError(w, err)
+			return
+		}
+		writeJSON(w, "/api/team/{code}/roster", roster)
+	})
+
+	r.HandleFunc("/api/team/{code}/schedule", func(w http.ResponseWriter, r *http.Request) {
+		seasonKey, err := seasonParam(r, "season")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if seasonKey == "" {
+			seasonKey = season.FromDate(time.Now()).Key()
+		}
+
+		ts, err := schedule.ForTeam(db, mux.Vars(r)["code"], seasonKey)
+		if err != nil {
+			writeQueryError(w, err)
+			return
+		}
+		writeJSON(w, "/api/team/{code}/schedule", ts)
+	})
 
-		var results []PlayerStat
-		for rows.Next() {
-			var p PlayerStat
-			if err := rows.Scan(
-				&p.Season, &p.SeasonType, &p.Name, &p.Team, &p.Pos, &p.Age, &p.G, &p.GS, &p.MP,
-				&p.FG, &p.FGA, &p.FGPct, &p.FG3, &p.FG3A, &p.FG3Pct,
-				&p.FT, &p.FTA, &p.FTPct, &p.ORB, &p.DRB, &p.TRB,
-				&p.AST, &p.STL, &p.BLK, &p.TOV, &p.PF, &p.PTS,
-			); err != nil {
-				http.Error(w, "scan failed: "+err.Error(), http.StatusInternalServerError)
+	r.HandleFunc("/api/h2h/{teamA}/{teamB}", func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		q := h2h.Query{
+			TeamA:  vars["teamA"],
+			TeamB:  vars["teamB"],
+			Scrape: r.URL.Query().Get("scrape") == "true",
+		}
+		var err error
+		if q.From, err = seasonParam(r, "from"); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if q.To, err = seasonParam(r, "to"); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		h, err := h2h.Between(db, q)
+		if err != nil {
+			writeQueryError(w, err)
+			return
+		}
+		writeJSON(w, "/api/h2h/{teamA}/{teamB}", h)
+	})
+
+	r.HandleFunc("/api/standings", func(w http.ResponseWriter, r *http.Request) {
+		date := time.Now()
+		if d := r.URL.Query().Get("date"); d != "" {
+			var err error
+			if date, err = season.ParseDay(d); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
 				return
 			}
-			results = append(results, p)
 		}
 
-		w.Header().Set("Content-Type", "application/json")
-		if err := json.NewEncoder(w).Encode(results); err != nil {
-			log.Printf("encoding /api/player response: %v", err)
+		st, err := standings.On(db, date)
+		if err != nil {
+			writeQueryError(w, err)
+			return
+		}
+		writeJSON(w, "/api/standings", st)
+	})
+
+	r.HandleFunc("/api/ratings", func(w http.ResponseWriter, r *http.Request) {
+		date := time.Now()
+		if d := r.URL.Query().Get("date"); d != "" {
+			var err error
+			if date, err = season.ParseDay(d); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+
+		rt, err := ratings.At(db, date)
+		if err != nil {
+			writeQueryError(w, err)
+			return
+		}
+		writeJSON(w, "/api/ratings", rt)
+	})
+
+	r.HandleFunc("/api/team/{code}/ratings", func(w http.ResponseWriter, r *http.Request) {
+		seasonKey, err := seasonParam(r, "season")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if seasonKey == "" {
+			seasonKey = season.FromDate(time.Now()).Key()
+		}
+
+		tr, err := ratings.ForTeam(db, mux.Vars(r)["code"], seasonKey)
+		if err != nil {
+			writeQueryError(w, err)
+			return
+		}
+		writeJSON(w, "/api/team/{code}/ratings", tr)
+	})
+
+	r.HandleFunc("/api/simulate", func(w http.ResponseWriter, r *http.Request) {
+		q := simulate.Query{Seed: uint64(time.Now().UnixNano())}
+		var err error
+		if q.Season, err = seasonParam(r, "season"); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if q.Season == "" {
+			q.Season = season.FromDate(time.Now()).Key()
+		}
+		if q.Runs, err = intParam(r, "runs", simulate.DefaultRuns, 1, simulate.MaxRuns); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if v := r.URL.Query().Get("seed"); v != "" {
+			if q.Seed, err = strconv.ParseUint(v, 10, 64); err != nil {
+				http.Error(w, "seed must be a non-negative integer", http.StatusBadRequest)
+				return
+			}
+		}
+
+		sim, err := simulate.Run(db, q)
+		if err != nil {
+			writeQueryError(w, err)
+			return
+		}
+		writeJSON(w, "/api/simulate", sim)
+	})
+
+	r.HandleFunc("/api/playoffs/{season}/bracket", func(w http.ResponseWriter, r *http.Request) {
+		s, err := season.Parse(mux.Vars(r)["season"])
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		b, err := playoffs.ForSeason(db, s)
+		if errors.Is(err, playoffs.ErrNoPlayoffs) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			writeQueryError(w, err)
+			return
+		}
+		writeJSON(w, "/api/playoffs/{season}/bracket", b)
+	})
+
+	r.HandleFunc("/api/game/{id}/pbp", func(w http.ResponseWriter, r *http.Request) {
+		id := mux.Vars(r)["id"]
+		if !pbp.GameID.MatchString(id) {
+			http.Error(w, "game id must look like 202403110NYK", http.StatusBadRequest)
+			return
+		}
+
+		p, err := playByPlay(db, "/api/game/{id}/pbp", canonicalGameID(id))
+		if err != nil {
+			writePlayByPlayError(w, err)
+			return
+		}
+		writeJSON(w, "/api/game/{id}/pbp", p)
+	})
+
+	r.HandleFunc("/api/game/{id}/lineups", func(w http.ResponseWriter, r *http.Request) {
+		id := mux.Vars(r)["id"]
+		if !pbp.GameID.MatchString(id) {
+			http.Error(w, "game id must look like 202403110NYK", http.StatusBadRequest)
+			return
+		}
+
+		p, err := playByPlay(db, "/api/game/{id}/lineups", canonicalGameID(id))
+		if err != nil {
+			writePlayByPlayError(w, err)
+			return
+		}
+		b := lineups.NewBook()
+		b.Add(p)
+		writeJSON(w, "/api/game/{id}/lineups", b.Report("", 0))
+	})
+
+	r.HandleFunc("/api/game/{id}/winprob", func(w http.ResponseWriter, r *http.Request) {
+		id := mux.Vars(r)["id"]
+		if !pbp.GameID.MatchString(id) {
+			http.Error(w, "game id must look like 202403110NYK", http.StatusBadRequest)
+			return
+		}
+
+		p, err := playByPlay(db, "/api/game/{id}/winprob", canonicalGameID(id))
+		if err != nil {
+			writePlayByPlayError(w, err)
+			return
+		}
+		pregame, err := winprob.Pregame(db, p)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, "/api/game/{id}/winprob", winprob.Default.ForGame(p, pregame))
+	})
+
+	r.HandleFunc("/api/team/{code}/lineups", func(w http.ResponseWriter, r *http.Request) {
+		seasonKey, err := seasonParam(r, "season")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if seasonKey == "" {
+			seasonKey = season.FromDate(time.Now()).Key()
+		}
+		minMinutes, err := intParam(r, "min_minutes", 10, 0, 4000)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		report, err := lineups.ForTeam(db, mux.Vars(r)["code"], seasonKey, r.URL.Query().Get("season_type"), float64(minMinutes))
+		if err != nil {
+			writeQueryError(w, err)
+			return
+		}
+		writeJSON(w, "/api/team/{code}/lineups", report)
+	})
+
+	r.HandleFunc("/api/player/{id}/clutch", func(w http.ResponseWriter, r *http.Request) {
+		seasonKey, err := seasonParam(r, "season")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if seasonKey == "" {
+			seasonKey = season.FromDate(time.Now()).Key()
+		}
+
+		c, err := clutch.ForPlayer(db, mux.Vars(r)["id"], seasonKey, r.URL.Query().Get("season_type"))
+		if errors.Is(err, stats.ErrNotFound) {
+			http.Error(w, "no stored games for this player in "+season.Label(seasonKey), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			writeQueryError(w, err)
+			return
+		}
+		writeJSON(w, "/api/player/{id}/clutch", c)
+	})
+
+	r.HandleFunc("/api/team/{code}/clutch", func(w http.ResponseWriter, r *http.Request) {
+		seasonKey, err := seasonParam(r, "season")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
 		}
+		if seasonKey == "" {
+			seasonKey = season.FromDate(time.Now()).Key()
+		}
+
+		c, err := clutch.ForTeam(db, mux.Vars(r)["code"], seasonKey, r.URL.Query().Get("season_type"))
+		if err != nil {
+			writeQueryError(w, err)
+			return
+		}
+		writeJSON(w, "/api/team/{code}/clutch", c)
+	})
+
+	r.HandleFunc("/api/league/averages", func(w http.ResponseWriter, r *http.Request) {
+		from, err := seasonParam(r, "from")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		to, err := seasonParam(r, "to")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if to == "" {
+			to = season.FromDate(time.Now()).Key()
+		}
+		if from != "" && from > to {
+			http.Error(w, "from must not be after to", http.StatusBadRequest)
+			return
+		}
+
+		avg, err := stats.LeagueAveragesBetween(db, from, to, r.URL.Query().Get("season_type"))
+		if err != nil {
+			writeQueryError(w, err)
+			return
+		}
+		writeJSON(w, "/api/league/averages", avg)
 	})
 
 	r.HandleFunc("/today", func(w http.ResponseWriter, r *http.Request) {
@@ -136,6 +734,14 @@ func main() {
 
 	r.HandleFunc("/api/scoreboard", func(w http.ResponseWriter, r *http.Request) {
 		date := r.URL.Query().Get("date")
+		if date != "" {
+			t, err := season.ParseDay(date)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			date = t.Format("20060102")
+		}
 		scoreboard, err := espn.FetchScoreboard(date)
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -150,8 +756,17 @@ func main() {
 	})
 
 	r.HandleFunc("/boxscore/{year}/{month}/{day}", func(w http.ResponseWriter, r *http.Request) {
-		vars := mux.Vars(r)
-		boxScore, err := teamboxscore.ExtractBoxScore(vars["month"], vars["day"], vars["year"])
+		year, month, day, err := dateVars(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		date, _ := time.Parse("20060102", year+month+day)
+		if scores, err := warehouse.Scores(db, date); stored("/boxscore/{year}/{month}/{day}", err) {
+			writeJSON(w, "/boxscore/{year}/{month}/{day}", teamboxscore.AllTeamBoxScore{BoxScores: scores})
+			return
+		}
+		boxScore, err := teamboxscore.ExtractBoxScore(month, day, year)
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
@@ -168,8 +783,19 @@ func main() {
 	})
 
 	r.HandleFunc("/boxscore/{year}/{month}/{day}/{awayteam}/{hometeam}", func(w http.ResponseWriter, r *http.Request) {
+		year, month, day, err := dateVars(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		vars := mux.Vars(r)
-		gameSummary, err := teamtotals.ExtractGameSummary(vars["month"], vars["day"], vars["year"], vars["awayteam"], vars["hometeam"])
+		date, _ := time.Parse("20060102", year+month+day)
+		gameID := warehouse.GameID(date, vars["hometeam"])
+		if totals, err := warehouse.TeamTotals(db, gameID); stored("/boxscore/{year}/{month}/{day}/{awayteam}/{hometeam}", err) {
+			writeJSON(w, "/boxscore/{year}/{month}/{day}/{awayteam}/{hometeam}", totals)
+			return
+		}
+		gameSummary, err := teamtotals.ExtractGameSummary(month, day, year, vars["awayteam"], vars["hometeam"])
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
@@ -178,8 +804,19 @@ func main() {
 	})
 
 	r.HandleFunc("/boxscore/{year}/{month}/{day}/{awayteam}/{hometeam}/player", func(w http.ResponseWriter, r *http.Request) {
+		year, month, day, err := dateVars(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		vars := mux.Vars(r)
-		gameSummary, err := playertotals.ExtractPlayerSummary(vars["month"], vars["day"], vars["year"], vars["awayteam"], vars["hometeam"])
+		date, _ := time.Parse("20060102", year+month+day)
+		gameID := warehouse.GameID(date, vars["hometeam"])
+		if lines, err := warehouse.PlayerTotals(db, gameID); stored("/boxscore/{year}/{month}/{day}/{awayteam}/{hometeam}/player", err) {
+			writeJSON(w, "/boxscore/{year}/{month}/{day}/{awayteam}/{hometeam}/player", lines)
+			return
+		}
+		gameSummary, err := playertotals.ExtractPlayerSummary(month, day, year, vars["awayteam"], vars["hometeam"])
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
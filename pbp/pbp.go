@@ -0,0 +1,295 @@
+// Package pbp scrapes basketball-reference's play-by-play pages into typed
+// events and stores them in the pbp_events table.
+package pbp
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+
+	"github.com/umanchanda/NBA-API/internal/fetch"
+)
+
+const baseURL = "https://www.basketball-reference.com"
+
+// Event types.
+const (
+	TypeShot        = "shot"
+	TypeFreeThrow   = "free_throw"
+	TypeRebound     = "rebound"
+	TypeTurnover    = "turnover"
+	TypeFoul        = "foul"
+	TypeSub         = "sub"
+	TypeTimeout     = "timeout"
+	TypeJumpBall    = "jump_ball"
+	TypeViolation   = "violation"
+	TypePeriodStart = "period_start"
+	TypePeriodEnd   = "period_end"
+	TypeOther       = "other"
+)
+
+// Event is one play. Team is the code of the team whose column the play is
+// listed in, empty for neutral plays such as the start of a period. The
+// player ids depend on the type:
+//
+//	shot        Player shoots, Player2 assists a make or blocks a miss
+//	free_throw  Player shoots
+//	rebound     Player rebounds, empty for a team rebound
+//	turnover    Player turns it over, Player2 steals
+//	foul        Player fouls, Player2 drew the foul
+//	sub         Player enters, Player2 leaves
+//	jump_ball   Player and Player2 jump, Player3 gains possession
+//	violation   Player
+//
+// Detail is the shot type ("jump shot", "layup"), free throw kind
+// ("1 of 2", "technical"), rebound side ("offensive"), turnover reason or
+// foul type. AwayScore and HomeScore are the score after the play.
+type Event struct {
+	Seq         int     `json:"seq"`
+	Period      int     `json:"period"`
+	Clock       string  `json:"clock"`
+	Remaining   float64 `json:"remaining"` // seconds left in the period
+	Team        string  `json:"team,omitempty"`
+	Home        bool    `json:"home"`
+	Type        string  `json:"type"`
+	Detail      string  `json:"detail,omitempty"`
+	Made        *bool   `json:"made,omitempty"`
+	ShotValue   int     `json:"shot_value,omitempty"`
+	Distance    *int    `json:"distance,omitempty"` // feet, 0 at the rim
+	Points      int     `json:"points"`
+	Player      string  `json:"player,omitempty"`
+	Player2     string  `json:"player2,omitempty"`
+	Player3     string  `json:"player3,omitempty"`
+	AwayScore   int     `json:"away_score"`
+	HomeScore   int     `json:"home_score"`
+	Description string  `json:"description"`
+}
+
+// PeriodLength returns the length in seconds of a period: 12 minutes for
+// quarters, 5 for overtimes.
+func PeriodLength(period int) float64 {
+	if period > 4 {
+		return 300
+	}
+	return 720
+}
+
+// Elapsed returns the seconds played in the game before the event.
+func (e Event) Elapsed() float64 {
+	var t float64
+	for p := 1; p < e.Period; p++ {
+		t += PeriodLength(p)
+	}
+	return t + PeriodLength(e.Period) - e.Remaining
+}
+
+// PlayByPlay is a game's events in order.
+type PlayByPlay struct {
+	GameID string  `json:"game_id"`
+	Away   string  `json:"away"`
+	Home   string  `json:"home"`
+	Events []Event `json:"events"`
+}
+
+var (
+	// GameID matches basketball-reference game ids, e.g. 202403110NYK.
+	GameID = regexp.MustCompile(`^\d{8}0[A-Z]{3}$`)
+
+	playerHref = regexp.MustCompile(`/players/\w/(\w+)\.html`)
+	teamHref   = regexp.MustCompile(`/teams/(\w+)/`)
+	periodID   = regexp.MustCompile(`^q(\d+)$`)
+
+	// Player links are rewritten to "@id" before matching.
+	shotText      = regexp.MustCompile(`^@(\w+) (makes|misses) ([23])-pt ([a-z\- ]+?)(?: from (\d+) ft| (at rim))?(?: \((assist|block) by @(\w+)\))?$`)
+	freeThrowText = regexp.MustCompile(`^@(\w+) (makes|misses) (?:([a-z ]+?) )?free throw(?: (\d of \d))?`)
+	reboundText   = regexp.MustCompile(`^(Offensive|Defensive) rebound by (?:@(\w+)|Team)`)
+	turnoverText  = regexp.MustCompile(`^Turnover by (?:@(\w+)|Team) \(([^;)]+)(?:; steal by @(\w+))?\)`)
+	foulText      = regexp.MustCompile(`^(.+? foul(?: type \d)?) by (?:@(\w+)|Team)(?: \(drawn by @(\w+)\))?`)
+	subText       = regexp.MustCompile(`^@(\w+) enters the game for @(\w+)`)
+	jumpBallText  = regexp.MustCompile(`^Jump ball: @(\w+) vs\. @(\w+)(?: \(@(\w+) gains possession\))?`)
+	violationText = regexp.MustCompile(`^Violation by (?:@(\w+)|Team) \(([^)]+)\)`)
+	timeoutText   = regexp.MustCompile(`(?i)\btimeout\b`)
+	scoreText     = regexp.MustCompile(`^(\d+)-(\d+)$`)
+)
+
+// Scrape fetches and parses a game's play-by-play page. A game with no page
+// is fetch.ErrNotFound.
+func Scrape(gameID string) (PlayByPlay, error) {
+	if !GameID.MatchString(gameID) {
+		return PlayByPlay{}, fmt.Errorf("%q is not a game id", gameID)
+	}
+	html, err := fetch.HTML(baseURL + "/boxscores/pbp/" + gameID + ".html")
+	if err != nil {
+		return PlayByPlay{}, err
+	}
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(html))
+	if err != nil {
+		return PlayByPlay{}, fmt.Errorf("parsing HTML: %w", err)
+	}
+	return Parse(gameID, doc)
+}
+
+// Parse reads the play-by-play table of a page.
+func Parse(gameID string, doc *goquery.Document) (PlayByPlay, error) {
+	if !GameID.MatchString(gameID) {
+		return PlayByPlay{}, fmt.Errorf("%q is not a game id", gameID)
+	}
+	p := PlayByPlay{GameID: gameID, Home: gameID[9:], Events: []Event{}}
+	// The scorebox lists the away team first.
+	doc.Find("div.scorebox strong a").EachWithBreak(func(_ int, a *goquery.Selection) bool {
+		href, _ := a.Attr("href")
+		if m := teamHref.FindStringSubmatch(href); m != nil {
+			p.Away = m[1]
+			return false
+		}
+		return true
+	})
+	if p.Away == "" || p.Away == p.Home {
+		return PlayByPlay{}, fmt.Errorf("%s: no away team in the scorebox", gameID)
+	}
+
+	rows := doc.Find("table#pbp tr")
+	if rows.Length() == 0 {
+		return PlayByPlay{}, fmt.Errorf("%s: no play-by-play table", gameID)
+	}
+	// Periods are numbered by the "q1", "q2", ... header rows, or counted
+	// from the "Start of" rows on pages without them.
+	period, away, home := 0, 0, 0
+	headers := false
+	rows.Each(func(_ int, row *goquery.Selection) {
+		if id, _ := row.Attr("id"); periodID.MatchString(id) {
+			period, _ = strconv.Atoi(periodID.FindStringSubmatch(id)[1])
+			headers = true
+			return
+		}
+		cells := row.Find("td")
+		if cells.Length() == 0 {
+			return
+		}
+		e := Event{Clock: strings.TrimSpace(cells.Eq(0).Text())}
+		e.Remaining = clockSeconds(e.Clock)
+
+		var cell *goquery.Selection
+		switch cells.Length() {
+		case 2:
+			cell = cells.Eq(1)
+			if strings.HasPrefix(clean(cell.Text()), "Start of") && !headers {
+				period++
+			}
+		case 6:
+			if cell, e.Team = cells.Eq(1), p.Away; clean(cell.Text()) == "" {
+				cell, e.Team, e.Home = cells.Eq(5), p.Home, true
+			}
+			if m := scoreText.FindStringSubmatch(strings.TrimSpace(cells.Eq(3).Text())); m != nil {
+				away, _ = strconv.Atoi(m[1])
+				home, _ = strconv.Atoi(m[2])
+			}
+		default:
+			return
+		}
+		if e.Description = clean(cell.Text()); e.Description == "" {
+			return
+		}
+		e.Period = max(period, 1)
+		e.AwayScore, e.HomeScore = away, home
+		classify(&e, linked(cell))
+		e.Seq = len(p.Events) + 1
+		p.Events = append(p.Events, e)
+	})
+	if len(p.Events) == 0 {
+		return PlayByPlay{}, fmt.Errorf("%s: no plays found", gameID)
+	}
+	return p, nil
+}
+
+// clockSeconds converts an "11:45.0" game clock to seconds.
+func clockSeconds(clock string) float64 {
+	m, s, _ := strings.Cut(clock, ":")
+	mins, _ := strconv.Atoi(m)
+	secs, _ := strconv.ParseFloat(s, 64)
+	return float64(mins)*60 + secs
+}
+
+// linked returns a cell's text with every player link replaced by "@id".
+func linked(cell *goquery.Selection) string {
+	var b strings.Builder
+	cell.Contents().Each(func(_ int, n *goquery.Selection) {
+		if n.Is("a") {
+			href, _ := n.Attr("href")
+			if m := playerHref.FindStringSubmatch(href); m != nil {
+				b.WriteString("@" + m[1])
+				return
+			}
+		}
+		b.WriteString(n.Text())
+	})
+	return clean(b.String())
+}
+
+// clean collapses whitespace, including non-breaking spaces.
+func clean(s string) string {
+	return strings.Join(strings.Fields(strings.ReplaceAll(s, "\u00a0", " ")), " ")
+}
+
+// classify sets the event's type, detail and players from its text, with
+// player links written as "@id".
+func classify(e *Event, text string) {
+	e.Type = TypeOther
+	made := func(verb string) *bool {
+		v := verb == "makes"
+		return &v
+	}
+
+	switch {
+	case shotText.MatchString(text):
+		m := shotText.FindStringSubmatch(text)
+		e.Type, e.Player, e.Made, e.Detail = TypeShot, m[1], made(m[2]), m[4]
+		e.ShotValue, _ = strconv.Atoi(m[3])
+		if m[5] != "" {
+			d, _ := strconv.Atoi(m[5])
+			e.Distance = &d
+		} else if m[6] != "" {
+			d := 0
+			e.Distance = &d
+		}
+		e.Player2 = m[8]
+		if *e.Made {
+			e.Points = e.ShotValue
+		}
+	case freeThrowText.MatchString(text):
+		m := freeThrowText.FindStringSubmatch(text)
+		e.Type, e.Player, e.Made, e.ShotValue = TypeFreeThrow, m[1], made(m[2]), 1
+		e.Detail = strings.TrimSpace(m[3] + " " + m[4])
+		if *e.Made {
+			e.Points = 1
+		}
+	case reboundText.MatchString(text):
+		m := reboundText.FindStringSubmatch(text)
+		e.Type, e.Detail, e.Player = TypeRebound, strings.ToLower(m[1]), m[2]
+	case turnoverText.MatchString(text):
+		m := turnoverText.FindStringSubmatch(text)
+		e.Type, e.Player, e.Detail, e.Player2 = TypeTurnover, m[1], m[2], m[3]
+	case subText.MatchString(text):
+		m := subText.FindStringSubmatch(text)
+		e.Type, e.Player, e.Player2 = TypeSub, m[1], m[2]
+	case jumpBallText.MatchString(text):
+		m := jumpBallText.FindStringSubmatch(text)
+		e.Type, e.Player, e.Player2, e.Player3 = TypeJumpBall, m[1], m[2], m[3]
+	case violationText.MatchString(text):
+		m := violationText.FindStringSubmatch(text)
+		e.Type, e.Player, e.Detail = TypeViolation, m[1], m[2]
+	case foulText.MatchString(text):
+		m := foulText.FindStringSubmatch(text)
+		e.Type, e.Detail, e.Player, e.Player2 = TypeFoul, strings.ToLower(m[1]), m[2], m[3]
+	case timeoutText.MatchString(text):
+		e.Type = TypeTimeout
+	case strings.HasPrefix(text, "Start of"):
+		e.Type = TypePeriodStart
+	case strings.HasPrefix(text, "End of"):
+		e.Type = TypePeriodEnd
+	}
+}
@@ -0,0 +1,124 @@
+package pbp
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func fixture(t *testing.T, edit func(string) string) *goquery.Document {
+	t.Helper()
+	html, err := os.ReadFile("testdata/202403110NYK.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(edit(string(html))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+func same(s string) string { return s }
+
+func TestParse(t *testing.T) {
+	p, err := Parse("202403110NYK", fixture(t, same))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Away != "BOS" || p.Home != "NYK" {
+		t.Fatalf("teams = %s @ %s, want BOS @ NYK", p.Away, p.Home)
+	}
+
+	yes, no := true, false
+	intp := func(v int) *int { return &v }
+	want := []Event{
+		{Type: TypePeriodStart},
+		{Type: TypeJumpBall, Player: "porzikr01", Player2: "randlju01", Player3: "holidjr01"},
+		{Team: "BOS", Type: TypeShot, Detail: "jump shot", Made: &yes, ShotValue: 2, Distance: intp(14), Points: 2, Player: "tatumja01", Player2: "brownja02", AwayScore: 2},
+		{Team: "NYK", Home: true, Type: TypeShot, Detail: "layup", Made: &no, ShotValue: 2, Distance: intp(0), Player: "brunsja01", Player2: "porzikr01", AwayScore: 2},
+		{Team: "BOS", Type: TypeRebound, Detail: "defensive", Player: "holidjr01", AwayScore: 2},
+		{Team: "BOS", Type: TypeTurnover, Detail: "bad pass", Player: "holidjr01", Player2: "brunsja01", AwayScore: 2},
+		{Team: "BOS", Type: TypeFoul, Detail: "shooting foul", Player: "holidjr01", Player2: "brunsja01", AwayScore: 2},
+		{Team: "NYK", Home: true, Type: TypeFreeThrow, Detail: "1 of 2", Made: &yes, ShotValue: 1, Points: 1, Player: "brunsja01", AwayScore: 2, HomeScore: 1},
+		{Team: "NYK", Home: true, Type: TypeFreeThrow, Detail: "2 of 2", Made: &no, ShotValue: 1, Player: "brunsja01", AwayScore: 2, HomeScore: 1},
+		{Team: "BOS", Type: TypeRebound, Detail: "defensive", AwayScore: 2, HomeScore: 1},
+		{Team: "NYK", Home: true, Type: TypeFreeThrow, Detail: "technical", Made: &yes, ShotValue: 1, Points: 1, Player: "brunsja01", AwayScore: 2, HomeScore: 2},
+		{Team: "NYK", Home: true, Type: TypeFreeThrow, Detail: "flagrant 1 of 2", Made: &yes, ShotValue: 1, Points: 1, Player: "brunsja01", AwayScore: 2, HomeScore: 3},
+		{Team: "BOS", Type: TypeShot, Detail: "jump shot", Made: &yes, ShotValue: 3, Distance: intp(26), Points: 3, Player: "tatumja01", AwayScore: 5, HomeScore: 3},
+		{Team: "BOS", Type: TypeSub, Player: "whitede01", Player2: "tatumja01", AwayScore: 5, HomeScore: 3},
+		{Team: "NYK", Home: true, Type: TypeTimeout, AwayScore: 5, HomeScore: 3},
+		{Team: "BOS", Type: TypeViolation, Detail: "delay of game", AwayScore: 5, HomeScore: 3},
+		{Type: TypePeriodEnd, AwayScore: 5, HomeScore: 3},
+		{Type: TypePeriodStart, AwayScore: 5, HomeScore: 3},
+		{Team: "NYK", Home: true, Type: TypeShot, Detail: "hook shot", Made: &yes, ShotValue: 2, Distance: intp(5), Points: 2, Player: "hartjo01", AwayScore: 5, HomeScore: 5},
+	}
+	if len(p.Events) != len(want) {
+		t.Fatalf("got %d events, want %d", len(p.Events), len(want))
+	}
+	for i, w := range want {
+		got := p.Events[i]
+		if got.Seq != i+1 {
+			t.Errorf("event %d: seq = %d", i+1, got.Seq)
+		}
+		// Only the parsed fields are compared; the clock and text are
+		// checked separately.
+		got.Seq, got.Period, got.Clock, got.Remaining, got.Description = 0, 0, "", 0, ""
+		if !reflect.DeepEqual(got, w) {
+			t.Errorf("event %d (%s):\n got %+v\nwant %+v", i+1, p.Events[i].Description, got, w)
+		}
+	}
+
+	e := p.Events[2]
+	if e.Clock != "11:41.0" || e.Remaining != 701 {
+		t.Errorf("clock = %q, remaining = %v, want 11:41.0 and 701", e.Clock, e.Remaining)
+	}
+	if e.Description != "J. Tatum makes 2-pt jump shot from 14 ft (assist by J. Brown)" {
+		t.Errorf("description = %q", e.Description)
+	}
+	if e := p.Events[len(p.Events)-1]; e.Remaining != 691.5 || e.Elapsed() != 748.5 {
+		t.Errorf("last play remaining %v, elapsed %v, want 691.5 and 748.5", e.Remaining, e.Elapsed())
+	}
+}
+
+func TestParsePeriods(t *testing.T) {
+	headers, err := Parse("202403110NYK", fixture(t, same))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Without the q1, q2 header rows periods are counted from the
+	// "Start of" rows.
+	counted, err := Parse("202403110NYK", fixture(t, func(s string) string {
+		return strings.NewReplacer(`id="q1"`, "", `id="q2"`, "").Replace(s)
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []PlayByPlay{headers, counted} {
+		var periods []int
+		for _, e := range p.Events {
+			periods = append(periods, e.Period)
+		}
+		want := []int{1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 2, 2}
+		if !reflect.DeepEqual(periods, want) {
+			t.Errorf("periods = %v, want %v", periods, want)
+		}
+	}
+}
+
+func TestParseRejects(t *testing.T) {
+	if _, err := Parse("NYK", fixture(t, same)); err == nil {
+		t.Error("parsed with a bad game id")
+	}
+	if _, err := Parse("202403110NYK", fixture(t, func(s string) string {
+		return strings.Replace(s, `<table id="pbp">`, `<table id="other">`, 1)
+	})); err == nil {
+		t.Error("parsed a page without a play-by-play table")
+	}
+	if _, err := Parse("202403110BOS", fixture(t, same)); err == nil {
+		t.Error("parsed a page whose away team is the home team")
+	}
+}
@@ -0,0 +1,165 @@
+package pbp
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+// ErrNotStored is returned by Load for a game whose play-by-play has not
+// been stored.
+var ErrNotStored = fmt.Errorf("play-by-play not stored")
+
+// CreateTable creates the pbp_events table if it does not exist.
+func CreateTable(db *sql.DB) error {
+	stmts := []string{
+		`CREATE TABLE IF NOT EXISTS pbp_events (
+			game_id     TEXT NOT NULL,
+			seq         INTEGER NOT NULL,
+			period      INTEGER NOT NULL,
+			clock       TEXT NOT NULL,
+			remaining   DOUBLE PRECISION NOT NULL,
+			team        TEXT NOT NULL,
+			home        BOOLEAN NOT NULL,
+			event_type  TEXT NOT NULL,
+			detail      TEXT NOT NULL,
+			made        BOOLEAN,
+			shot_value  INTEGER NOT NULL,
+			distance    INTEGER,
+			points      INTEGER NOT NULL,
+			player_id   TEXT NOT NULL,
+			player2_id  TEXT NOT NULL,
+			player3_id  TEXT NOT NULL,
+			away_score  INTEGER NOT NULL,
+			home_score  INTEGER NOT NULL,
+			description TEXT NOT NULL,
+			PRIMARY KEY (game_id, seq)
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_pbp_events_player ON pbp_events (player_id)`,
+	}
+	for _, stmt := range stmts {
+		if _, err := db.Exec(stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Store saves a game's play-by-play, replacing it if it was stored before.
+func Store(db *sql.DB, p PlayByPlay) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM pbp_events WHERE game_id = $1`, p.GameID); err != nil {
+		return fmt.Errorf("replacing %s: %w", p.GameID, err)
+	}
+	stmt, err := tx.Prepare(`INSERT INTO pbp_events (
+			game_id, seq, period, clock, remaining, team, home, event_type, detail, made,
+			shot_value, distance, points, player_id, player2_id, player3_id,
+			away_score, home_score, description
+		) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18,$19)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	for _, e := range p.Events {
+		_, err := stmt.Exec(p.GameID, e.Seq, e.Period, e.Clock, e.Remaining, e.Team, e.Home, e.Type, e.Detail, e.Made,
+			e.ShotValue, e.Distance, e.Points, e.Player, e.Player2, e.Player3,
+			e.AwayScore, e.HomeScore, e.Description)
+		if err != nil {
+			return fmt.Errorf("insert failed for %s event %d: %w", p.GameID, e.Seq, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// eventColumns are the pbp_events columns of an Event, in scan order.
+const eventColumns = `seq, period, clock, remaining, team, home, event_type, detail, made,
+	shot_value, distance, points, player_id, player2_id, player3_id,
+	away_score, home_score, description`
+
+// Load returns a game's stored play-by-play.
+func Load(db *sql.DB, gameID string) (PlayByPlay, error) {
+	games, err := LoadGames(db, []string{gameID})
+	if err != nil {
+		return PlayByPlay{}, err
+	}
+	if len(games) == 0 {
+		return PlayByPlay{}, ErrNotStored
+	}
+	return games[0], nil
+}
+
+// LoadGames returns the stored play-by-play of each of gameIDs that has
+// one, in game id order.
+func LoadGames(db *sql.DB, gameIDs []string) ([]PlayByPlay, error) {
+	rows, err := db.Query(`SELECT game_id, `+eventColumns+`
+		FROM pbp_events
+		WHERE game_id = ANY($1)
+		ORDER BY game_id, seq`, pq.Array(gameIDs))
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	var games []PlayByPlay
+	for rows.Next() {
+		var id string
+		var e Event
+		var made sql.NullBool
+		var distance sql.NullInt64
+		err := rows.Scan(&id, &e.Seq, &e.Period, &e.Clock, &e.Remaining, &e.Team, &e.Home, &e.Type, &e.Detail, &made,
+			&e.ShotValue, &distance, &e.Points, &e.Player, &e.Player2, &e.Player3,
+			&e.AwayScore, &e.HomeScore, &e.Description)
+		if err != nil {
+			return nil, fmt.Errorf("scan failed: %w", err)
+		}
+		if made.Valid {
+			e.Made = &made.Bool
+		}
+		if distance.Valid {
+			d := int(distance.Int64)
+			e.Distance = &d
+		}
+		if n := len(games); n == 0 || games[n-1].GameID != id {
+			games = append(games, PlayByPlay{GameID: id, Home: id[9:]})
+		}
+		g := &games[len(games)-1]
+		if !e.Home && e.Team != "" {
+			g.Away = e.Team
+		}
+		g.Events = append(g.Events, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	return games, nil
+}
+
+// Missing returns the stored box score games of a season that have no
+// play-by-play yet, oldest first.
+func Missing(db *sql.DB, seasonKey string) ([]string, error) {
+	rows, err := db.Query(`SELECT game_id
+		FROM games g
+		WHERE season = $1
+			AND NOT EXISTS (SELECT 1 FROM pbp_events p WHERE p.game_id = g.game_id)
+		ORDER BY date, game_id`, seasonKey)
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan failed: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
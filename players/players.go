@@ -0,0 +1,273 @@
+package players
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// minScore is the lowest match score Search returns.
+const minScore = 0.3
+
+// Player is one distinct player from playerstats.
+type Player struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	FirstSeason string `json:"first_season"`
+	LastSeason  string `json:"last_season"`
+	LastTeam    string `json:"last_team"`
+
+	seasons int
+}
+
+// Match is a search result with its relevance score between 0 and 1.
+type Match struct {
+	Player
+	Score float64 `json:"score"`
+	Alias string  `json:"alias,omitempty"`
+}
+
+// Index is an in-memory index of every player in playerstats.
+type Index struct {
+	players []Player
+	names   []string // normalized names, parallel to players
+	byID    map[string]int
+	aliases map[string]string // normalized alias -> player id
+	prefix  []prefixKey       // sorted, see Suggest
+}
+
+// prefixKey is a normalized name, or the tail of one starting at a word,
+// pointing back at its player.
+type prefixKey struct {
+	key    string
+	player int
+}
+
+// isAggregate reports whether team is a basketball-reference multi-team
+// row ("TOT" on older pages, "2TM", "3TM", ... on newer ones).
+func isAggregate(team string) bool {
+	return team == "TOT" || (len(team) == 3 && strings.HasSuffix(team, "TM"))
+}
+
+// Load builds an Index from the playerstats and player_aliases tables.
+func Load(db *sql.DB) (*Index, error) {
+	rows, err := db.Query(`SELECT player_id, name, season, team
+		FROM playerstats
+		WHERE player_id IS NOT NULL AND player_id <> ''
+		ORDER BY season ASC, season_type DESC, id ASC`)
+	if err != nil {
+		return nil, fmt.Errorf("loading players: %w", err)
+	}
+	defer rows.Close()
+
+	ix := &Index{byID: make(map[string]int), aliases: make(map[string]string)}
+	for rows.Next() {
+		var id, name, season, team string
+		if err := rows.Scan(&id, &name, &season, &team); err != nil {
+			return nil, fmt.Errorf("scanning player: %w", err)
+		}
+		i, ok := ix.byID[id]
+		if !ok {
+			i = len(ix.players)
+			ix.byID[id] = i
+			ix.players = append(ix.players, Player{ID: id, Name: name, FirstSeason: season})
+			ix.names = append(ix.names, Normalize(name))
+		}
+		p := &ix.players[i]
+		if p.LastSeason != season {
+			p.seasons++
+		}
+		p.LastSeason = season
+		if !isAggregate(team) {
+			p.LastTeam = team
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("loading players: %w", err)
+	}
+
+	aliasRows, err := db.Query(`SELECT alias, player_id FROM player_aliases`)
+	if err != nil {
+		return nil, fmt.Errorf("loading aliases: %w", err)
+	}
+	defer aliasRows.Close()
+	for aliasRows.Next() {
+		var alias, id string
+		if err := aliasRows.Scan(&alias, &id); err != nil {
+			return nil, fmt.Errorf("scanning alias: %w", err)
+		}
+		ix.aliases[Normalize(alias)] = id
+	}
+	if err := aliasRows.Err(); err != nil {
+		return nil, fmt.Errorf("loading aliases: %w", err)
+	}
+
+	ix.buildPrefixes()
+	return ix, nil
+}
+
+// buildPrefixes indexes every name under its full form and under each word
+// onward, so "lebr" and "jam" both reach "lebron james".
+func (ix *Index) buildPrefixes() {
+	ix.prefix = ix.prefix[:0]
+	for i, n := range ix.names {
+		for {
+			ix.prefix = append(ix.prefix, prefixKey{key: n, player: i})
+			sp := strings.IndexByte(n, ' ')
+			if sp < 0 {
+				break
+			}
+			n = n[sp+1:]
+		}
+	}
+	sort.Slice(ix.prefix, func(i, j int) bool {
+		return ix.prefix[i].key < ix.prefix[j].key
+	})
+}
+
+// Len returns the number of players in the index.
+func (ix *Index) Len() int {
+	return len(ix.players)
+}
+
+// Get returns the player with the given basketball-reference id.
+func (ix *Index) Get(id string) (Player, bool) {
+	i, ok := ix.byID[id]
+	if !ok {
+		return Player{}, false
+	}
+	return ix.players[i], true
+}
+
+// score rates how well the normalized query q matches the normalized name n.
+// Exact matches score 1, names where every query word is a prefix of a name
+// word score from 0.8, and anything else falls back to trigram similarity,
+// taking the better of whole-string and word-by-word comparison so that
+// "jokic" still finds "nikola jokic".
+func score(q, n string) float64 {
+	if q == n {
+		return 1
+	}
+	qWords, nWords := strings.Fields(q), strings.Fields(n)
+
+	prefix := true
+	for _, qw := range qWords {
+		found := false
+		for _, nw := range nWords {
+			if strings.HasPrefix(nw, qw) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			prefix = false
+			break
+		}
+	}
+	if prefix {
+		return 0.8 + 0.15*float64(len(q))/float64(len(n))
+	}
+
+	var words float64
+	for _, qw := range qWords {
+		best := 0.0
+		for _, nw := range nWords {
+			if s := similarity(qw, nw); s > best {
+				best = s
+			}
+		}
+		words += best
+	}
+	words /= float64(len(qWords))
+	return 0.75 * max(similarity(q, n), words)
+}
+
+// Search returns up to limit players matching q, best match first. Aliases
+// such as "shaq" match their player with a score of 0.95.
+func (ix *Index) Search(q string, limit int) []Match {
+	q = Normalize(q)
+	if q == "" {
+		return nil
+	}
+
+	var matches []Match
+	aliasID := ix.aliases[q]
+	for i, n := range ix.names {
+		p := ix.players[i]
+		if p.ID == aliasID {
+			matches = append(matches, Match{Player: p, Score: 0.95, Alias: q})
+			continue
+		}
+		if s := score(q, n); s >= minScore {
+			matches = append(matches, Match{Player: p, Score: s})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].LastSeason > matches[j].LastSeason
+	})
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	for i := range matches {
+		matches[i].Score = float64(int(matches[i].Score*1000+0.5)) / 1000
+	}
+	return matches
+}
+
+// Suggest returns up to limit distinct players whose name, or any word of it
+// onward, starts with q. Players whose full name matches come first, then
+// players with longer careers, then the most recent.
+func (ix *Index) Suggest(q string, limit int) []Player {
+	q = Normalize(q)
+	if q == "" {
+		return nil
+	}
+
+	type hit struct {
+		player int
+		full   bool
+	}
+	seen := make(map[int]int)
+	var hits []hit
+	start := sort.Search(len(ix.prefix), func(i int) bool {
+		return ix.prefix[i].key >= q
+	})
+	for _, k := range ix.prefix[start:] {
+		if !strings.HasPrefix(k.key, q) {
+			break
+		}
+		full := strings.HasPrefix(ix.names[k.player], q)
+		if j, ok := seen[k.player]; ok {
+			hits[j].full = hits[j].full || full
+			continue
+		}
+		seen[k.player] = len(hits)
+		hits = append(hits, hit{player: k.player, full: full})
+	}
+
+	sort.Slice(hits, func(i, j int) bool {
+		a, b := hits[i], hits[j]
+		if a.full != b.full {
+			return a.full
+		}
+		pa, pb := ix.players[a.player], ix.players[b.player]
+		if pa.seasons != pb.seasons {
+			return pa.seasons > pb.seasons
+		}
+		return pa.LastSeason > pb.LastSeason
+	})
+	if limit > 0 && len(hits) > limit {
+		hits = hits[:limit]
+	}
+
+	out := make([]Player, len(hits))
+	for i, h := range hits {
+		out[i] = ix.players[h.player]
+	}
+	return out
+}
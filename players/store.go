@@ -0,0 +1,77 @@
+package players
+
+import (
+	"database/sql"
+	"log"
+	"sync/atomic"
+	"time"
+)
+
+// Store holds the current Index and swaps in a new one whenever playerstats
+// changes, so the server picks up a seed run without restarting.
+type Store struct {
+	db        *sql.DB
+	current   atomic.Pointer[Index]
+	version   string
+	onRefresh []func() error
+}
+
+// NewStore builds the first Index. If that fails the store starts out empty
+// and the error is returned so the caller can log it; Watch keeps retrying.
+// onRefresh functions run after every rebuild, so other indexes built from
+// playerstats stay in step with this one.
+func NewStore(db *sql.DB, onRefresh ...func() error) (*Store, error) {
+	s := &Store{db: db, onRefresh: onRefresh}
+	s.current.Store(&Index{})
+	return s, s.Refresh()
+}
+
+// Index returns the current index. It is safe for concurrent use.
+func (s *Store) Index() *Index {
+	return s.current.Load()
+}
+
+// tableVersion changes whenever rows are inserted into or deleted from
+// playerstats or player_aliases.
+func (s *Store) tableVersion() (string, error) {
+	var version string
+	err := s.db.QueryRow(`SELECT
+		(SELECT COUNT(*) || ':' || COALESCE(MAX(id), 0) FROM playerstats) || ':' ||
+		(SELECT COUNT(*) FROM player_aliases)`).Scan(&version)
+	return version, err
+}
+
+// Refresh rebuilds the index if playerstats has changed since the last build.
+func (s *Store) Refresh() error {
+	version, err := s.tableVersion()
+	if err != nil {
+		return err
+	}
+	if version == s.version {
+		return nil
+	}
+
+	ix, err := Load(s.db)
+	if err != nil {
+		return err
+	}
+	s.current.Store(ix)
+	log.Printf("indexed %d players", ix.Len())
+	for _, fn := range s.onRefresh {
+		if err := fn(); err != nil {
+			return err
+		}
+	}
+	s.version = version
+	return nil
+}
+
+// Watch calls Refresh every interval until the process exits. Refresh is
+// cheap when nothing changed: one COUNT query.
+func (s *Store) Watch(interval time.Duration) {
+	for range time.Tick(interval) {
+		if err := s.Refresh(); err != nil {
+			log.Printf("refreshing player index: %v", err)
+		}
+	}
+}
@@ -15,6 +15,7 @@ const baseURL = "https://www.basketball-reference.com"
 // PlayerTotals represents a player box score from a single game
 type PlayerTotals struct {
 	Team                 string `json:"team,omitempty"`
+	PlayerID             string `json:"player_id,omitempty"`
 	Name                 string `json:"name,omitempty"`
 	MinutesPlayed        string `json:"minutes_played,omitempty"`
 	FieldGoals           string `json:"field_goals,omitempty"`
@@ -52,8 +53,10 @@ type PlayerTotalsGame struct {
 // extractPlayerRow builds a PlayerTotals from a single table row selection.
 func extractPlayerRow(row *goquery.Selection, team string) PlayerTotals {
 	td := func(i int) string { return row.Find("td").Eq(i).Text() }
+	id, _ := row.Find("th").Attr("data-append-csv")
 	return PlayerTotals{
 		Team:                 team,
+		PlayerID:             id,
 		Name:                 row.Find("a").Text(),
 		MinutesPlayed:        td(0),
 		FieldGoals:           td(1),
@@ -78,23 +81,39 @@ func extractPlayerRow(row *goquery.Selection, team string) PlayerTotals {
 	}
 }
 
-// extractTeamPlayers collects starters (rows 0-4) and reserves (rows 6-15) for a team.
+// extractTeamPlayers collects a team's starters, the rows above the
+// "Reserves" header, and everyone listed below it.
 func extractTeamPlayers(doc *goquery.Document, team string) PlayerTotalsTeam {
-	rows := doc.Find("#box-" + team + "-game-basic tbody tr")
-
 	starters := make([]PlayerTotals, 0, 5)
-	for i := 0; i < 5; i++ {
-		starters = append(starters, extractPlayerRow(rows.Eq(i), team))
-	}
-
 	reserves := make([]PlayerTotals, 0, 10)
-	for i := 6; i < 16; i++ {
-		reserves = append(reserves, extractPlayerRow(rows.Eq(i), team))
-	}
+	inReserves := false
+	doc.Find("#box-" + team + "-game-basic tbody tr").Each(func(_ int, row *goquery.Selection) {
+		if row.HasClass("thead") {
+			inReserves = true
+			return
+		}
+		p := extractPlayerRow(row, team)
+		if p.Name == "" {
+			return
+		}
+		if inReserves {
+			reserves = append(reserves, p)
+		} else {
+			starters = append(starters, p)
+		}
+	})
 
 	return PlayerTotalsTeam{Starters: starters, Reserves: reserves}
 }
 
+// Players reads both teams' player lines from a parsed box score page.
+func Players(doc *goquery.Document, awayTeam, homeTeam string) []PlayerTotalsTeam {
+	return []PlayerTotalsTeam{
+		extractTeamPlayers(doc, awayTeam),
+		extractTeamPlayers(doc, homeTeam),
+	}
+}
+
 func ExtractPlayerSummary(month, day, year, awayTeam, homeTeam string) (string, error) {
 	url := baseURL + "/boxscores/" + year + month + day + "0" + homeTeam + ".html"
 	html, err := fetch.HTML(url)
@@ -107,12 +126,7 @@ func ExtractPlayerSummary(month, day, year, awayTeam, homeTeam string) (string,
 		return "", fmt.Errorf("parsing HTML: %w", err)
 	}
 
-	allPlayers := []PlayerTotalsTeam{
-		extractTeamPlayers(doc, awayTeam),
-		extractTeamPlayers(doc, homeTeam),
-	}
-
-	result, err := json.Marshal(allPlayers)
+	result, err := json.Marshal(Players(doc, awayTeam, homeTeam))
 	if err != nil {
 		return "", fmt.Errorf("encoding JSON: %w", err)
 	}
@@ -0,0 +1,363 @@
+// Package playoffs builds a season's playoff bracket from its playoff game
+// results.
+package playoffs
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/umanchanda/NBA-API/franchise"
+	"github.com/umanchanda/NBA-API/schedule"
+	"github.com/umanchanda/NBA-API/season"
+	"github.com/umanchanda/NBA-API/standings"
+	"github.com/umanchanda/NBA-API/stats"
+	"github.com/umanchanda/NBA-API/warehouse"
+)
+
+// ErrNoPlayoffs is returned when a season has no playoff games stored or
+// on its schedule page.
+var ErrNoPlayoffs = fmt.Errorf("no playoff games found")
+
+// Game is one game of a series.
+type Game struct {
+	GameID   string `json:"game_id"`
+	Date     string `json:"date"`
+	Away     string `json:"away"`
+	Home     string `json:"home"`
+	AwayPts  int    `json:"away_pts"`
+	HomePts  int    `json:"home_pts"`
+	Winner   string `json:"winner"` // franchise id
+	BoxScore string `json:"box_score"`
+}
+
+// SeriesTeam is one side of a series. Seed is 0 when the season's
+// standings are not known. Stats aggregates the team's box scores over the
+// series games found in the warehouse.
+type SeriesTeam struct {
+	Franchise string               `json:"franchise"`
+	Team      string               `json:"team"`
+	Name      string               `json:"name"`
+	Seed      int                  `json:"seed,omitempty"`
+	Wins      int                  `json:"wins"`
+	Stats     *warehouse.Aggregate `json:"stats"`
+}
+
+// Series is one playoff series. Teams[0] had home court.
+type Series struct {
+	Conference string        `json:"conference,omitempty"`
+	Teams      [2]SeriesTeam `json:"teams"`
+	BestOf     int           `json:"best_of"`
+	Complete   bool          `json:"complete"`
+	Winner     string        `json:"winner,omitempty"` // franchise id
+	Summary    string        `json:"summary"`          // "BOS wins 4-1", "Series tied 2-2"
+	Games      []Game        `json:"games"`
+	StatsGames int           `json:"stats_games"`
+	Missing    int           `json:"missing"`
+}
+
+// Round is one round of the bracket.
+type Round struct {
+	Number int      `json:"number"`
+	Name   string   `json:"name"`
+	Series []Series `json:"series"`
+}
+
+// Bracket is the /api/playoffs/{season}/bracket response. Source is
+// "stored" or, when no playoff results are stored, "scraped" from the
+// season's schedule pages.
+type Bracket struct {
+	Season      string  `json:"season"`
+	SeasonLabel string  `json:"season_label"`
+	Source      string  `json:"source"`
+	Champion    string  `json:"champion,omitempty"`
+	Rounds      []Round `json:"rounds"`
+}
+
+// roundNames names rounds counting back from the Finals.
+var roundNames = []string{"Finals", "Conference Finals", "Conference Semifinals", "First Round"}
+
+// roundsIn returns the number of playoff rounds in the season ending in
+// year: four since the field grew to ten teams in 1975 (and in 1950), three
+// before.
+func roundsIn(year int) int {
+	if year >= 1975 || year == 1950 {
+		return 4
+	}
+	return 3
+}
+
+// bestOf returns the length of a series in a season with the given number
+// of rounds, or 0 before 1957-58, when early round lengths changed from
+// year to year; those series are sized from their result instead. The
+// Finals have always been best-of-seven, and so has every other round
+// outside the first since 1957-58.
+func bestOf(year, round, rounds int) int {
+	switch {
+	case round == rounds:
+		return 7
+	case year < 1958:
+		return 0
+	case round != 1:
+		return 7
+	case year <= 1960, year >= 1975 && year <= 1983:
+		return 3
+	case year <= 1967, year >= 1984 && year <= 2002:
+		return 5
+	}
+	return 7
+}
+
+// series accumulates one series' games.
+type series struct {
+	high, low string // franchise ids; high was at home in game 1
+	round     int
+	results   []schedule.Result
+}
+
+// group splits playoff results, oldest first, into series. A series is
+// one round past the furthest either team had got, so a team that had a
+// first round bye starts in the second round.
+func group(results []schedule.Result) []*series {
+	var all []*series
+	byPair := make(map[[2]string]*series)
+	played := make(map[string]int)
+	for _, r := range results {
+		away, okA := franchise.Lookup(r.Away)
+		home, okH := franchise.Lookup(r.Home)
+		if !okA || !okH {
+			continue
+		}
+		pair := [2]string{away.ID, home.ID}
+		if pair[0] > pair[1] {
+			pair[0], pair[1] = pair[1], pair[0]
+		}
+		sr := byPair[pair]
+		if sr == nil {
+			round := max(played[home.ID], played[away.ID]) + 1
+			sr = &series{high: home.ID, low: away.ID, round: round}
+			byPair[pair] = sr
+			all = append(all, sr)
+			played[home.ID], played[away.ID] = round, round
+		}
+		sr.results = append(sr.results, r)
+	}
+	return all
+}
+
+// ForSeason builds the playoff bracket of a season: every series grouped
+// by round, with seeds from the final regular season standings where they
+// are known, the games and result of each series, and both teams'
+// aggregate box scores.
+func ForSeason(db *sql.DB, s season.Season) (Bracket, error) {
+	b := Bracket{Season: s.Key(), SeasonLabel: s.String(), Source: "stored", Rounds: []Round{}}
+	results, err := schedule.Results(db, schedule.ResultQuery{
+		FromSeason: s.Key(),
+		ToSeason:   s.Key(),
+		SeasonType: stats.SeasonTypePlayoffs,
+	})
+	if err != nil {
+		return Bracket{}, err
+	}
+	if len(results) == 0 {
+		if results, err = scrape(s); err != nil {
+			return Bracket{}, err
+		}
+		b.Source = "scraped"
+	}
+	if len(results) == 0 {
+		return Bracket{}, ErrNoPlayoffs
+	}
+	year, _ := strconv.Atoi(s.Key())
+
+	all := group(results)
+
+	seeds, err := regularSeasonSeeds(db, s)
+	if err != nil {
+		return Bracket{}, err
+	}
+	// From 2020-21 seeds 7 to 10 went through the play-in, so a team that
+	// came out of it takes its seed from its first round opponent.
+	if year >= 2021 {
+		for _, sr := range all {
+			if sr.round != 1 {
+				continue
+			}
+			if hs := seeds[sr.high]; hs <= 2 && seeds[sr.low] >= 7 {
+				seeds[sr.low] = 9 - hs
+			}
+		}
+	}
+	var ids []string
+	for _, sr := range all {
+		for _, r := range sr.results {
+			ids = append(ids, r.GameID)
+		}
+	}
+	lines, err := warehouse.TeamLines(db, ids)
+	if err != nil {
+		return Bracket{}, err
+	}
+
+	// Rounds are named from the season's format, not from the rounds found,
+	// so a postseason still under way keeps its first round the First Round.
+	found := 0
+	for _, sr := range all {
+		found = max(found, sr.round)
+	}
+	rounds := roundsIn(year)
+	for n := 1; n <= found; n++ {
+		round := Round{Number: n, Series: []Series{}}
+		if back := rounds - n; back >= 0 && back < len(roundNames) {
+			round.Name = roundNames[back]
+		}
+		for _, sr := range all {
+			if sr.round == n {
+				round.Series = append(round.Series, sr.build(year, rounds, seeds, lines))
+			}
+		}
+		sort.SliceStable(round.Series, func(i, j int) bool {
+			return round.Series[i].Conference < round.Series[j].Conference
+		})
+		b.Rounds = append(b.Rounds, round)
+	}
+	if last := b.Rounds[len(b.Rounds)-1]; len(last.Series) == 1 && last.Series[0].Complete && last.Name == "Finals" {
+		b.Champion = last.Series[0].Winner
+	}
+	return b, nil
+}
+
+// build assembles the series from its games.
+func (sr *series) build(year, rounds int, seeds map[string]int, lines map[string][]warehouse.TeamLine) Series {
+	out := Series{BestOf: bestOf(year, sr.round, rounds), Games: []Game{}}
+	for i, id := range []string{sr.high, sr.low} {
+		f, _ := franchise.Lookup(id)
+		era, _ := f.EraFor(year)
+		out.Teams[i] = SeriesTeam{Franchise: id, Team: era.Code, Name: era.Name, Seed: seeds[id], Stats: &warehouse.Aggregate{}}
+	}
+	hf, _ := franchise.Lookup(sr.high)
+	lf, _ := franchise.Lookup(sr.low)
+	if hc, _, _ := hf.Alignment(year); hc != "" {
+		if lc, _, _ := lf.Alignment(year); hc == lc {
+			out.Conference = hc
+		}
+	}
+
+	for _, r := range sr.results {
+		winner, _ := franchise.Lookup(r.Winner())
+		g := Game{
+			GameID:   r.GameID,
+			Date:     r.Date.Format("2006-01-02"),
+			Away:     r.Away,
+			Home:     r.Home,
+			AwayPts:  r.AwayPts,
+			HomePts:  r.HomePts,
+			Winner:   winner.ID,
+			BoxScore: "/playerstats/" + r.Date.Format("2006/01/02") + "/" + r.Away + "/" + r.Home,
+		}
+		out.Games = append(out.Games, g)
+		if winner.ID == sr.high {
+			out.Teams[0].Wins++
+		} else {
+			out.Teams[1].Wins++
+		}
+
+		teams, ok := lines[r.GameID]
+		if !ok {
+			out.Missing++
+			continue
+		}
+		out.StatsGames++
+		for _, t := range teams {
+			if f, ok := franchise.Lookup(t.Team); ok && f.ID == sr.high {
+				out.Teams[0].Stats.Add(t.Line)
+			} else {
+				out.Teams[1].Stats.Add(t.Line)
+			}
+		}
+	}
+	out.Teams[0].Stats.Finish()
+	out.Teams[1].Stats.Finish()
+
+	if out.BestOf == 0 {
+		// Early seasons are long over, so the series went to the winner's
+		// last win.
+		out.BestOf = 2*max(out.Teams[0].Wins, out.Teams[1].Wins) - 1
+	}
+	need := out.BestOf/2 + 1
+	high, low := out.Teams[0], out.Teams[1]
+	score := func(a, b SeriesTeam) string { return strconv.Itoa(a.Wins) + "-" + strconv.Itoa(b.Wins) }
+	switch {
+	case high.Wins >= need:
+		out.Complete, out.Winner, out.Summary = true, high.Franchise, high.Team+" wins "+score(high, low)
+	case low.Wins >= need:
+		out.Complete, out.Winner, out.Summary = true, low.Franchise, low.Team+" wins "+score(low, high)
+	case high.Wins > low.Wins:
+		out.Summary = high.Team + " leads " + score(high, low)
+	case low.Wins > high.Wins:
+		out.Summary = low.Team + " leads " + score(low, high)
+	default:
+		out.Summary = "Series tied " + score(high, low)
+	}
+	return out
+}
+
+// regularSeasonSeeds returns each franchise's conference seed in the
+// final regular season standings, or nothing for seasons before the
+// current divisions.
+func regularSeasonSeeds(db *sql.DB, s season.Season) (map[string]int, error) {
+	seeds := make(map[string]int)
+	year, _ := strconv.Atoi(s.Key())
+	tb, err := standings.NewTable(year)
+	if err != nil {
+		return seeds, nil
+	}
+	results, err := schedule.Results(db, schedule.ResultQuery{
+		FromSeason: s.Key(),
+		ToSeason:   s.Key(),
+		SeasonType: stats.SeasonTypeRegular,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return seeds, nil
+	}
+	for _, r := range results {
+		tb.Add(r)
+	}
+	for _, conf := range tb.Seeds() {
+		for i, id := range conf {
+			seeds[id] = i + 1
+		}
+	}
+	return seeds, nil
+}
+
+// scrape reads a season's playoff results from its schedule pages without
+// storing them.
+func scrape(s season.Season) ([]schedule.Result, error) {
+	games, err := schedule.Scrape(s)
+	if err != nil {
+		return nil, err
+	}
+	var results []schedule.Result
+	for _, g := range games {
+		if g.SeasonType != stats.SeasonTypePlayoffs || g.AwayPts == nil || g.HomePts == nil {
+			continue
+		}
+		results = append(results, schedule.Result{
+			GameID:     g.ID,
+			Date:       g.Date,
+			Season:     g.Season,
+			SeasonType: g.SeasonType,
+			Away:       g.Away,
+			Home:       g.Home,
+			AwayPts:    *g.AwayPts,
+			HomePts:    *g.HomePts,
+		})
+	}
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Date.Before(results[j].Date) })
+	return results, nil
+}
@@ -0,0 +1,96 @@
+package playoffs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/umanchanda/NBA-API/schedule"
+	"github.com/umanchanda/NBA-API/warehouse"
+)
+
+// games plays a series on high's floor, daily from start: high wins the
+// first highWins games and low the rest.
+func games(start time.Time, high, low string, highWins, lowWins int) []schedule.Result {
+	var out []schedule.Result
+	for i := 0; i < highWins+lowWins; i++ {
+		r := schedule.Result{Date: start.AddDate(0, 0, i), Away: low, Home: high, AwayPts: 100, HomePts: 110}
+		if i >= highWins {
+			r.AwayPts, r.HomePts = 110, 100
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
+// TestGroupByes plays the 1977-78 East and Finals: Philadelphia and San
+// Antonio had first round byes, so their first series are the Conference
+// Semifinals.
+func TestGroupByes(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(1978, 4, d, 0, 0, 0, 0, time.UTC) }
+	var results []schedule.Result
+	results = append(results, games(day(11), "WSB", "ATL", 2, 0)...)
+	results = append(results, games(day(12), "CLE", "NYK", 0, 2)...)
+	results = append(results, games(day(16), "SAS", "WSB", 2, 4)...)
+	results = append(results, games(day(17), "PHI", "NYK", 4, 0)...)
+	results = append(results, games(day(30), "PHI", "WSB", 2, 4)...)
+	results = append(results, games(day(30).AddDate(0, 0, 20), "SEA", "WSB", 3, 4)...)
+
+	all := group(results)
+	want := []struct {
+		high, low string
+		round     int
+		bestOf    int
+		summary   string
+	}{
+		{"WAS", "ATL", 1, 3, "WSB wins 2-0"},
+		{"CLE", "NYK", 1, 3, "NYK wins 2-0"},
+		{"SAS", "WAS", 2, 7, "WSB wins 4-2"},
+		{"PHI", "NYK", 2, 7, "PHI wins 4-0"},
+		{"PHI", "WAS", 3, 7, "WSB wins 4-2"},
+		{"OKC", "WAS", 4, 7, "WSB wins 4-3"},
+	}
+	if len(all) != len(want) {
+		t.Fatalf("got %d series, want %d", len(all), len(want))
+	}
+	for i, w := range want {
+		sr := all[i]
+		got := sr.build(1978, roundsIn(1978), nil, map[string][]warehouse.TeamLine{})
+		if sr.high != w.high || sr.low != w.low || sr.round != w.round {
+			t.Errorf("series %d = %s-%s in round %d, want %s-%s in round %d", i, sr.high, sr.low, sr.round, w.high, w.low, w.round)
+		}
+		if got.BestOf != w.bestOf || !got.Complete || got.Summary != w.summary {
+			t.Errorf("%s-%s: best of %d, complete %v, %q; want best of %d, %q",
+				sr.high, sr.low, got.BestOf, got.Complete, got.Summary, w.bestOf, w.summary)
+		}
+	}
+}
+
+func TestBestOf(t *testing.T) {
+	tests := []struct {
+		year, round, want int
+	}{
+		{1950, 4, 7}, // Finals
+		{1950, 1, 0}, // sized from the result
+		{1959, 1, 3},
+		{1959, 2, 7},
+		{1965, 1, 5},
+		{1970, 1, 7},
+		{1978, 1, 3},
+		{1978, 2, 7},
+		{1990, 1, 5},
+		{2002, 1, 5},
+		{2003, 1, 7},
+		{2024, 3, 7},
+	}
+	for _, tt := range tests {
+		if got := bestOf(tt.year, tt.round, roundsIn(tt.year)); got != tt.want {
+			t.Errorf("bestOf(%d, %d) = %d, want %d", tt.year, tt.round, got, tt.want)
+		}
+	}
+
+	// Before 1957-58 a series is as long as the winner needed.
+	sr := &series{high: "NYK", low: "BOS", round: 1, results: games(time.Date(1951, 3, 20, 0, 0, 0, 0, time.UTC), "NYK", "BOS", 2, 0)}
+	if got := sr.build(1951, roundsIn(1951), nil, map[string][]warehouse.TeamLine{}); got.BestOf != 3 || !got.Complete {
+		t.Errorf("1951 series: best of %d, complete %v, want a complete best of 3", got.BestOf, got.Complete)
+	}
+}
@@ -0,0 +1,115 @@
+// Package ratings rates teams from stored results: game-by-game Elo and the
+// Simple Rating System.
+package ratings
+
+import (
+	"math"
+
+	"github.com/umanchanda/NBA-API/franchise"
+	"github.com/umanchanda/NBA-API/schedule"
+)
+
+// Elo parameters, after FiveThirtyEight's NBA model.
+const (
+	InitialElo    = 1500.0
+	ExpansionElo  = 1300.0 // franchises that join after the first rated season
+	MeanElo       = 1505.0 // ratings regress toward this between seasons
+	Regression    = 0.25   // share of the gap to MeanElo closed each offseason
+	K             = 20.0
+	HomeAdvantage = 100.0
+)
+
+// WinProbability is the chance the home team wins, given both ratings.
+func WinProbability(home, away float64) float64 {
+	return 1 / (1 + math.Pow(10, -(home+HomeAdvantage-away)/400))
+}
+
+// movMultiplier scales an update by the margin of victory, damped when the
+// favourite wins so blowouts by strong teams don't inflate their rating.
+// diff is the winner's rating minus the loser's, home advantage included.
+func movMultiplier(margin int, diff float64) float64 {
+	return math.Pow(float64(margin)+3, 0.8) / (7.5 + 0.006*diff)
+}
+
+// Elo rates franchises game by game. Results must be played in order.
+type Elo struct {
+	ratings map[string]float64 // by franchise id
+	first   string             // first season rated
+	season  string
+}
+
+// NewElo returns a rater with no games played.
+func NewElo() *Elo {
+	return &Elo{ratings: make(map[string]float64)}
+}
+
+// Rating returns a franchise's current rating.
+func (e *Elo) Rating(id string) float64 {
+	r, ok := e.ratings[id]
+	if !ok {
+		r = InitialElo
+		if e.first != "" && e.season != e.first {
+			r = ExpansionElo
+		}
+		e.ratings[id] = r
+	}
+	return r
+}
+
+// Season moves the rater to a season, regressing every rating toward
+// MeanElo if it is a new one.
+func (e *Elo) Season(key string) {
+	if key == e.season {
+		return
+	}
+	if e.first == "" {
+		e.first = key
+	}
+	if e.season != "" {
+		for id, r := range e.ratings {
+			e.ratings[id] = r + Regression*(MeanElo-r)
+		}
+	}
+	e.season = key
+}
+
+// Step is one rated game with both teams' ratings before and after it.
+type Step struct {
+	schedule.Result
+	AwayFranchise, HomeFranchise string
+	AwayBefore, HomeBefore       float64
+	AwayAfter, HomeAfter         float64
+	HomeWinProb                  float64
+}
+
+// Play rates one result. ok is false if either team is not a franchise.
+func (e *Elo) Play(r schedule.Result) (step Step, ok bool) {
+	away, okA := franchise.Lookup(r.Away)
+	home, okH := franchise.Lookup(r.Home)
+	if !okA || !okH {
+		return Step{}, false
+	}
+	e.Season(r.Season)
+
+	a, h := e.Rating(away.ID), e.Rating(home.ID)
+	p := WinProbability(h, a)
+	margin := r.HomePts - r.AwayPts
+	var shift float64
+	if margin > 0 {
+		shift = K * movMultiplier(margin, h+HomeAdvantage-a) * (1 - p)
+	} else {
+		shift = -K * movMultiplier(-margin, a-h-HomeAdvantage) * p
+	}
+	e.ratings[away.ID], e.ratings[home.ID] = a-shift, h+shift
+
+	return Step{
+		Result:        r,
+		AwayFranchise: away.ID,
+		HomeFranchise: home.ID,
+		AwayBefore:    a,
+		HomeBefore:    h,
+		AwayAfter:     a - shift,
+		HomeAfter:     h + shift,
+		HomeWinProb:   p,
+	}, true
+}
@@ -0,0 +1,91 @@
+package ratings
+
+import (
+	"math"
+	"testing"
+
+	"github.com/umanchanda/NBA-API/schedule"
+)
+
+func near(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
+
+func TestWinProbability(t *testing.T) {
+	if p := WinProbability(1500, 1500); math.Abs(p-0.640) > 0.001 {
+		t.Errorf("equal teams: home wins %.3f, want 0.640 from home court", p)
+	}
+	if p := WinProbability(1500-HomeAdvantage, 1500); !near(p, 0.5) {
+		t.Errorf("home court offset by the rating gap: %v, want 0.5", p)
+	}
+	for _, tt := range [][2]float64{{1500, 1500}, {1650, 1400}, {1320, 1710}} {
+		h, a := tt[0], tt[1]
+		// Swapping the teams and taking home court with them gives the
+		// other side of the same game.
+		if p, q := WinProbability(h, a), WinProbability(a-2*HomeAdvantage, h); !near(p+q, 1) {
+			t.Errorf("WinProbability(%v, %v) = %v, swapped = %v, want them to sum to 1", h, a, p, q)
+		}
+		if WinProbability(h+50, a) <= WinProbability(h, a) {
+			t.Errorf("a better home team is not more likely to win at %v-%v", h, a)
+		}
+	}
+}
+
+func TestPlayConservesPoints(t *testing.T) {
+	e := NewElo()
+	games := []schedule.Result{
+		{Season: "2024", Away: "BOS", Home: "NYK", AwayPts: 120, HomePts: 100},
+		{Season: "2024", Away: "NYK", Home: "PHI", AwayPts: 99, HomePts: 101},
+		{Season: "2024", Away: "PHI", Home: "BOS", AwayPts: 130, HomePts: 90},
+	}
+	for _, r := range games {
+		s, ok := e.Play(r)
+		if !ok {
+			t.Fatalf("%s @ %s not rated", r.Away, r.Home)
+		}
+		if !near(s.AwayBefore+s.HomeBefore, s.AwayAfter+s.HomeAfter) {
+			t.Errorf("%s @ %s: %v points before, %v after", r.Away, r.Home,
+				s.AwayBefore+s.HomeBefore, s.AwayAfter+s.HomeAfter)
+		}
+		if winnerGained := (r.HomePts > r.AwayPts) == (s.HomeAfter > s.HomeBefore); !winnerGained {
+			t.Errorf("%s @ %s: the winner lost rating", r.Away, r.Home)
+		}
+	}
+	if sum := e.Rating("BOS") + e.Rating("NYK") + e.Rating("PHI"); !near(sum, 3*InitialElo) {
+		t.Errorf("ratings sum to %v, want %v", sum, 3*InitialElo)
+	}
+	if _, ok := e.Play(schedule.Result{Season: "2024", Away: "XXX", Home: "BOS"}); ok {
+		t.Error("a game against an unknown team was rated")
+	}
+}
+
+func TestSeasonRegression(t *testing.T) {
+	e := NewElo()
+	e.Season("2023")
+	e.ratings["BOS"], e.ratings["DET"] = 1700, 1300
+
+	// Moving to the same season again does nothing.
+	e.Season("2023")
+	if e.Rating("BOS") != 1700 {
+		t.Fatalf("BOS = %v after re-entering the season, want 1700", e.Rating("BOS"))
+	}
+	e.Season("2024")
+	if want := 1700 + Regression*(MeanElo-1700); !near(e.Rating("BOS"), want) {
+		t.Errorf("BOS = %v, want %v", e.Rating("BOS"), want)
+	}
+	if want := 1300 + Regression*(MeanElo-1300); !near(e.Rating("DET"), want) {
+		t.Errorf("DET = %v, want %v", e.Rating("DET"), want)
+	}
+}
+
+func TestExpansionElo(t *testing.T) {
+	e := NewElo()
+	e.Play(schedule.Result{Season: "2004", Away: "BOS", Home: "NYK", AwayPts: 100, HomePts: 98})
+	// Teams first seen in the first rated season start at the initial
+	// rating, even if they play their first game later in it.
+	if r := e.Rating("MIA"); r != InitialElo {
+		t.Errorf("MIA in the first season = %v, want %v", r, InitialElo)
+	}
+	s, _ := e.Play(schedule.Result{Season: "2005", Away: "CHA", Home: "BOS", AwayPts: 90, HomePts: 100})
+	if s.AwayFranchise != "CHO" || s.AwayBefore != ExpansionElo {
+		t.Errorf("Charlotte's first game: %s rated %v, want CHO at %v", s.AwayFranchise, s.AwayBefore, ExpansionElo)
+	}
+}
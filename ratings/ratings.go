@@ -0,0 +1,265 @@
+package ratings
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/umanchanda/NBA-API/franchise"
+	"github.com/umanchanda/NBA-API/schedule"
+	"github.com/umanchanda/NBA-API/season"
+	"github.com/umanchanda/NBA-API/stats"
+)
+
+// TeamRating is one team's ratings entering a date. The record and SRS
+// cover the season's regular season games so far.
+type TeamRating struct {
+	Rank      int     `json:"rank"`
+	Franchise string  `json:"franchise"`
+	Team      string  `json:"team"`
+	Name      string  `json:"name"`
+	Elo       float64 `json:"elo"`
+	Wins      int     `json:"wins"`
+	Losses    int     `json:"losses"`
+	MOV       float64 `json:"mov"`
+	SOS       float64 `json:"sos"`
+	SRS       float64 `json:"srs"`
+}
+
+// Prediction is the pregame Elo forecast of a scheduled game.
+type Prediction struct {
+	GameID      string  `json:"game_id"`
+	Time        string  `json:"time,omitempty"` // Eastern
+	SeasonType  string  `json:"season_type"`
+	Away        string  `json:"away"`
+	Home        string  `json:"home"`
+	AwayName    string  `json:"away_name"`
+	HomeName    string  `json:"home_name"`
+	AwayElo     float64 `json:"away_elo"`
+	HomeElo     float64 `json:"home_elo"`
+	AwayWinProb float64 `json:"away_win_prob"`
+	HomeWinProb float64 `json:"home_win_prob"`
+	AwayPts     *int    `json:"away_pts,omitempty"`
+	HomePts     *int    `json:"home_pts,omitempty"`
+}
+
+// Ratings is the /api/ratings response.
+type Ratings struct {
+	Date        string       `json:"date"`
+	Season      string       `json:"season"`
+	SeasonLabel string       `json:"season_label"`
+	Teams       []TeamRating `json:"teams"`
+	Games       []Prediction `json:"games"`
+}
+
+func round(v float64, places int) float64 {
+	p := math.Pow(10, float64(places))
+	return math.Round(v*p) / p
+}
+
+// Through plays every stored result before date and returns the rater,
+// moved to date's season.
+func Through(db *sql.DB, date time.Time) (*Elo, error) {
+	results, err := schedule.Results(db, schedule.ResultQuery{Before: date})
+	if err != nil {
+		return nil, err
+	}
+	e := NewElo()
+	for _, r := range results {
+		e.Play(r)
+	}
+	e.Season(season.FromDate(date).Key())
+	return e, nil
+}
+
+// At rates every team entering date, ranked by Elo, and forecasts the
+// games scheduled that day.
+func At(db *sql.DB, date time.Time) (Ratings, error) {
+	date = time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	s := season.FromDate(date)
+	year, _ := strconv.Atoi(s.Key())
+
+	e, err := Through(db, date)
+	if err != nil {
+		return Ratings{}, err
+	}
+	regular, err := schedule.Results(db, schedule.ResultQuery{
+		FromSeason: s.Key(),
+		ToSeason:   s.Key(),
+		SeasonType: stats.SeasonTypeRegular,
+		Before:     date,
+	})
+	if err != nil {
+		return Ratings{}, err
+	}
+	srs := SimpleRatings(regular)
+
+	out := Ratings{
+		Date:        date.Format("2006-01-02"),
+		Season:      s.Key(),
+		SeasonLabel: s.String(),
+		Teams:       []TeamRating{},
+		Games:       []Prediction{},
+	}
+	for _, f := range franchise.All() {
+		era, ok := f.EraFor(year)
+		if !ok {
+			continue
+		}
+		r := srs[f.ID]
+		out.Teams = append(out.Teams, TeamRating{
+			Franchise: f.ID,
+			Team:      era.Code,
+			Name:      era.Name,
+			Elo:       round(e.Rating(f.ID), 1),
+			Wins:      r.Wins,
+			Losses:    r.Games - r.Wins,
+			MOV:       round(r.MOV, 2),
+			SOS:       round(r.SOS, 2),
+			SRS:       round(r.SRS, 2),
+		})
+	}
+	sort.SliceStable(out.Teams, func(i, j int) bool { return out.Teams[i].Elo > out.Teams[j].Elo })
+	for i := range out.Teams {
+		out.Teams[i].Rank = i + 1
+	}
+
+	games, err := schedule.Games(db, schedule.GameQuery{From: date, To: date.AddDate(0, 0, 1)})
+	if err != nil {
+		return Ratings{}, err
+	}
+	for _, g := range games {
+		away, okA := franchise.Lookup(g.Away)
+		home, okH := franchise.Lookup(g.Home)
+		if !okA || !okH {
+			continue
+		}
+		a, h := e.Rating(away.ID), e.Rating(home.ID)
+		p := WinProbability(h, a)
+		out.Games = append(out.Games, Prediction{
+			GameID:      g.ID,
+			Time:        g.StartTime,
+			SeasonType:  g.SeasonType,
+			Away:        g.Away,
+			Home:        g.Home,
+			AwayName:    g.AwayName,
+			HomeName:    g.HomeName,
+			AwayElo:     round(a, 1),
+			HomeElo:     round(h, 1),
+			AwayWinProb: round(1-p, 3),
+			HomeWinProb: round(p, 3),
+			AwayPts:     g.AwayPts,
+			HomePts:     g.HomePts,
+		})
+	}
+	return out, nil
+}
+
+// TeamGame is one game's effect on a team's Elo.
+type TeamGame struct {
+	GameID     string  `json:"game_id"`
+	Date       string  `json:"date"`
+	SeasonType string  `json:"season_type"`
+	HomeAway   string  `json:"home_away"`
+	Opp        string  `json:"opp"`
+	Result     string  `json:"result"`
+	TeamPts    int     `json:"team_pts"`
+	OppPts     int     `json:"opp_pts"`
+	OppElo     float64 `json:"opp_elo"`
+	WinProb    float64 `json:"win_prob"`
+	EloBefore  float64 `json:"elo_before"`
+	EloAfter   float64 `json:"elo_after"`
+}
+
+// TeamRatings is the /api/team/{code}/ratings response.
+type TeamRatings struct {
+	Franchise   string     `json:"franchise"`
+	Team        string     `json:"team"`
+	Name        string     `json:"name"`
+	Season      string     `json:"season"`
+	SeasonLabel string     `json:"season_label"`
+	StartElo    float64    `json:"start_elo"`
+	EndElo      float64    `json:"end_elo"`
+	Wins        int        `json:"wins"`
+	Losses      int        `json:"losses"`
+	MOV         float64    `json:"mov"`
+	SOS         float64    `json:"sos"`
+	SRS         float64    `json:"srs"`
+	Games       []TeamGame `json:"games"`
+}
+
+// ForTeam traces a team's Elo through a season, game by game, with its
+// regular season SRS.
+func ForTeam(db *sql.DB, code, seasonKey string) (TeamRatings, error) {
+	f, ok := franchise.Lookup(code)
+	if !ok {
+		return TeamRatings{}, stats.ErrBadQuery{Err: fmt.Errorf("unknown team %q", code)}
+	}
+	year, _ := strconv.Atoi(seasonKey)
+	era, ok := f.EraFor(year)
+	if !ok {
+		return TeamRatings{}, stats.ErrBadQuery{Err: fmt.Errorf("%s did not play in %s", f.ID, season.Label(seasonKey))}
+	}
+
+	results, err := schedule.Results(db, schedule.ResultQuery{ToSeason: seasonKey})
+	if err != nil {
+		return TeamRatings{}, err
+	}
+	e := NewElo()
+	var regular []schedule.Result
+	tr := TeamRatings{
+		Franchise:   f.ID,
+		Team:        era.Code,
+		Name:        era.Name,
+		Season:      seasonKey,
+		SeasonLabel: season.Label(seasonKey),
+		Games:       []TeamGame{},
+	}
+	for _, r := range results {
+		step, ok := e.Play(r)
+		if !ok || r.Season != seasonKey {
+			continue
+		}
+		if r.SeasonType == stats.SeasonTypeRegular {
+			regular = append(regular, r)
+		}
+		g := TeamGame{
+			GameID:     r.GameID,
+			Date:       r.Date.Format("2006-01-02"),
+			SeasonType: r.SeasonType,
+		}
+		switch f.ID {
+		case step.HomeFranchise:
+			g.HomeAway, g.Opp, g.TeamPts, g.OppPts = "home", r.Away, r.HomePts, r.AwayPts
+			g.OppElo, g.WinProb = step.AwayBefore, step.HomeWinProb
+			g.EloBefore, g.EloAfter = step.HomeBefore, step.HomeAfter
+		case step.AwayFranchise:
+			g.HomeAway, g.Opp, g.TeamPts, g.OppPts = "away", r.Home, r.AwayPts, r.HomePts
+			g.OppElo, g.WinProb = step.HomeBefore, 1-step.HomeWinProb
+			g.EloBefore, g.EloAfter = step.AwayBefore, step.AwayAfter
+		default:
+			continue
+		}
+		g.Result = "L"
+		if g.TeamPts > g.OppPts {
+			g.Result = "W"
+		}
+		g.OppElo, g.WinProb = round(g.OppElo, 1), round(g.WinProb, 3)
+		g.EloBefore, g.EloAfter = round(g.EloBefore, 1), round(g.EloAfter, 1)
+		tr.Games = append(tr.Games, g)
+	}
+
+	e.Season(seasonKey)
+	tr.EndElo = round(e.Rating(f.ID), 1)
+	tr.StartElo = tr.EndElo
+	if len(tr.Games) > 0 {
+		tr.StartElo = tr.Games[0].EloBefore
+	}
+	r := SimpleRatings(regular)[f.ID]
+	tr.Wins, tr.Losses = r.Wins, r.Games-r.Wins
+	tr.MOV, tr.SOS, tr.SRS = round(r.MOV, 2), round(r.SOS, 2), round(r.SRS, 2)
+	return tr, nil
+}
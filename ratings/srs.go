@@ -0,0 +1,89 @@
+package ratings
+
+import (
+	"math"
+
+	"github.com/umanchanda/NBA-API/franchise"
+	"github.com/umanchanda/NBA-API/schedule"
+)
+
+// SRS is a team's Simple Rating System line: average margin of victory,
+// strength of schedule and their sum, in points per game against an
+// average team.
+type SRS struct {
+	Games int
+	Wins  int
+	MOV   float64
+	SOS   float64
+	SRS   float64
+}
+
+// SimpleRatings solves the Simple Rating System over results, keyed by
+// franchise id. Each team's rating is its average margin plus the average
+// rating of its opponents; the ratings are found by iterating to a fixed
+// point and centred on zero.
+func SimpleRatings(results []schedule.Result) map[string]SRS {
+	margin := make(map[string]int)
+	wins := make(map[string]int)
+	opponents := make(map[string][]string)
+	for _, r := range results {
+		away, okA := franchise.Lookup(r.Away)
+		home, okH := franchise.Lookup(r.Home)
+		if !okA || !okH {
+			continue
+		}
+		d := r.HomePts - r.AwayPts
+		margin[home.ID] += d
+		margin[away.ID] -= d
+		if d > 0 {
+			wins[home.ID]++
+		} else {
+			wins[away.ID]++
+		}
+		opponents[home.ID] = append(opponents[home.ID], away.ID)
+		opponents[away.ID] = append(opponents[away.ID], home.ID)
+	}
+
+	mov := make(map[string]float64, len(opponents))
+	for id, opps := range opponents {
+		mov[id] = float64(margin[id]) / float64(len(opps))
+	}
+	srs := make(map[string]float64, len(mov))
+	for id, m := range mov {
+		srs[id] = m
+	}
+	for i := 0; i < 1000; i++ {
+		next := make(map[string]float64, len(srs))
+		var sum float64
+		for id, opps := range opponents {
+			var sos float64
+			for _, o := range opps {
+				sos += srs[o]
+			}
+			next[id] = mov[id] + sos/float64(len(opps))
+			sum += next[id]
+		}
+		mean := sum / float64(len(next))
+		var change float64
+		for id := range next {
+			next[id] -= mean
+			change = math.Max(change, math.Abs(next[id]-srs[id]))
+		}
+		srs = next
+		if change < 1e-6 {
+			break
+		}
+	}
+
+	out := make(map[string]SRS, len(srs))
+	for id, r := range srs {
+		out[id] = SRS{
+			Games: len(opponents[id]),
+			Wins:  wins[id],
+			MOV:   mov[id],
+			SOS:   r - mov[id],
+			SRS:   r,
+		}
+	}
+	return out
+}
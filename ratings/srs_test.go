@@ -0,0 +1,45 @@
+package ratings
+
+import (
+	"math"
+	"testing"
+
+	"github.com/umanchanda/NBA-API/schedule"
+)
+
+// TestSimpleRatings solves a three-team round robin by hand. In a full
+// round robin the opponents' ratings sum to minus the team's own, so the
+// fixed point is SRS = MOV * 2/3.
+func TestSimpleRatings(t *testing.T) {
+	results := []schedule.Result{
+		{Away: "NYK", Home: "BOS", AwayPts: 100, HomePts: 110},
+		{Away: "PHI", Home: "NYK", AwayPts: 96, HomePts: 100},
+		{Away: "BOS", Home: "PHI", AwayPts: 104, HomePts: 102},
+	}
+	want := map[string]SRS{
+		"BOS": {Games: 2, Wins: 2, MOV: 6, SOS: -2, SRS: 4},
+		"NYK": {Games: 2, Wins: 1, MOV: -3, SOS: 1, SRS: -2},
+		"PHI": {Games: 2, Wins: 0, MOV: -3, SOS: 1, SRS: -2},
+	}
+	got := SimpleRatings(results)
+	if len(got) != len(want) {
+		t.Fatalf("got %d teams, want %d", len(got), len(want))
+	}
+	for id, w := range want {
+		g := got[id]
+		if g.Games != w.Games || g.Wins != w.Wins || math.Abs(g.MOV-w.MOV) > 1e-6 ||
+			math.Abs(g.SOS-w.SOS) > 1e-5 || math.Abs(g.SRS-w.SRS) > 1e-5 {
+			t.Errorf("%s = %+v, want %+v", id, g, w)
+		}
+	}
+}
+
+func TestSimpleRatingsSkipsUnknownTeams(t *testing.T) {
+	got := SimpleRatings([]schedule.Result{
+		{Away: "NYK", Home: "BOS", AwayPts: 100, HomePts: 110},
+		{Away: "XXX", Home: "BOS", AwayPts: 50, HomePts: 150},
+	})
+	if len(got) != 2 || got["BOS"].Games != 1 || got["BOS"].MOV != 10 {
+		t.Errorf("ratings = %+v, want BOS at +10 over the one real game", got)
+	}
+}
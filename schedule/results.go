@@ -0,0 +1,98 @@
+package schedule
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Result is a finished game.
+type Result struct {
+	GameID     string
+	Date       time.Time
+	Season     string
+	SeasonType string
+	Away       string
+	Home       string
+	AwayPts    int
+	HomePts    int
+}
+
+// Winner returns the code of the team that won.
+func (r Result) Winner() string {
+	if r.HomePts > r.AwayPts {
+		return r.Home
+	}
+	return r.Away
+}
+
+// ResultQuery narrows Results. Empty fields do not filter.
+type ResultQuery struct {
+	FromSeason string
+	ToSeason   string
+	SeasonType string
+	Teams      []string  // games involving any of these codes
+	Before     time.Time // games played before this date
+}
+
+// Results returns finished games, oldest first, from the box score
+// warehouse and, for games not backfilled yet, the schedule.
+func Results(db *sql.DB, q ResultQuery) ([]Result, error) {
+	var conds []string
+	var args []interface{}
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return "$" + strconv.Itoa(len(args))
+	}
+	if q.FromSeason != "" {
+		conds = append(conds, "season::int >= "+arg(q.FromSeason)+"::int")
+	}
+	if q.ToSeason != "" {
+		conds = append(conds, "season::int <= "+arg(q.ToSeason)+"::int")
+	}
+	if q.SeasonType != "" {
+		conds = append(conds, "season_type = "+arg(q.SeasonType))
+	}
+	if len(q.Teams) > 0 {
+		p := arg(pq.Array(q.Teams))
+		conds = append(conds, "(away = ANY("+p+") OR home = ANY("+p+"))")
+	}
+	if !q.Before.IsZero() {
+		conds = append(conds, "date < "+arg(q.Before))
+	}
+	where := "TRUE"
+	if len(conds) > 0 {
+		where = strings.Join(conds, " AND ")
+	}
+
+	rows, err := db.Query(`SELECT game_id, date, season, season_type, away, home, away_pts, home_pts
+		FROM games
+		WHERE `+where+`
+		UNION ALL
+		SELECT game_id, date, season, season_type, away, home, away_pts, home_pts
+		FROM schedule s
+		WHERE `+where+` AND away_pts IS NOT NULL AND home_pts IS NOT NULL
+			AND NOT EXISTS (SELECT 1 FROM games g WHERE g.game_id = s.game_id)
+		ORDER BY date, game_id`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	var results []Result
+	for rows.Next() {
+		var r Result
+		if err := rows.Scan(&r.GameID, &r.Date, &r.Season, &r.SeasonType, &r.Away, &r.Home, &r.AwayPts, &r.HomePts); err != nil {
+			return nil, fmt.Errorf("scan failed: %w", err)
+		}
+		results = append(results, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	return results, nil
+}
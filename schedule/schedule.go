@@ -0,0 +1,306 @@
+// Package schedule scrapes basketball-reference's season schedule pages,
+// which list games before they are played, and stores them in the schedule
+// table.
+package schedule
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+
+	"github.com/umanchanda/NBA-API/internal/fetch"
+	"github.com/umanchanda/NBA-API/season"
+	"github.com/umanchanda/NBA-API/stats"
+	"github.com/umanchanda/NBA-API/warehouse"
+)
+
+const baseURL = "https://www.basketball-reference.com"
+
+// Game is one scheduled or played game. Scores are nil until it is played.
+type Game struct {
+	ID         string
+	Season     string
+	SeasonType string
+	Date       time.Time
+	StartTime  string // "19:30", Eastern, or "" when not announced
+	Away       string
+	Home       string
+	AwayName   string
+	HomeName   string
+	AwayPts    *int
+	HomePts    *int
+	Overtimes  string // "", "OT", "2OT", ...
+}
+
+var (
+	teamHref  = regexp.MustCompile(`/teams/(\w+)/`)
+	monthHref = regexp.MustCompile(`/leagues/NBA_\d{4}_games-\w+\.html`)
+	startTime = regexp.MustCompile(`^(\d{1,2}):(\d{2})\s*([ap])`)
+)
+
+// clock converts a "7:30p" start time to "19:30".
+func clock(s string) string {
+	m := startTime.FindStringSubmatch(strings.ToLower(strings.TrimSpace(s)))
+	if m == nil {
+		return ""
+	}
+	h, _ := strconv.Atoi(m[1])
+	if m[3] == "p" && h < 12 {
+		h += 12
+	}
+	if m[3] == "a" && h == 12 {
+		h = 0
+	}
+	return fmt.Sprintf("%02d:%s", h, m[2])
+}
+
+func document(url string) (*goquery.Document, error) {
+	html, err := fetch.HTML(url)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(html))
+	if err != nil {
+		return nil, fmt.Errorf("parsing HTML: %w", err)
+	}
+	return doc, nil
+}
+
+// Scrape fetches every month of a season's schedule, following the month
+// links on the NBA_YYYY_games.html page. Games after the "Playoffs"
+// separator row are playoff games, except play-in games, which are
+// recognized by their remarks wherever they are listed.
+func Scrape(s season.Season) ([]Game, error) {
+	index, err := document(baseURL + "/leagues/NBA_" + s.Key() + "_games.html")
+	if err != nil {
+		return nil, err
+	}
+	var months []string
+	index.Find("div.filter a").Each(func(_ int, a *goquery.Selection) {
+		if href, ok := a.Attr("href"); ok && monthHref.MatchString(href) {
+			months = append(months, baseURL+href)
+		}
+	})
+
+	var games []Game
+	playoffs := false
+	if len(months) == 0 {
+		games, _ = parseMonth(index, s, false)
+	}
+	for _, url := range months {
+		doc, err := document(url)
+		if err != nil {
+			return nil, err
+		}
+		var month []Game
+		month, playoffs = parseMonth(doc, s, playoffs)
+		games = append(games, month...)
+		time.Sleep(time.Second)
+	}
+	if len(games) == 0 {
+		return nil, fmt.Errorf("no games found on the %s schedule", s)
+	}
+	return games, nil
+}
+
+// parseMonth reads one month's schedule table. playoffs says whether the
+// "Playoffs" separator row came in an earlier month; the returned flag says
+// whether it has been passed by the end of this one. Play-in games keep
+// their type on either side of it.
+func parseMonth(doc *goquery.Document, s season.Season, playoffs bool) ([]Game, bool) {
+	var games []Game
+	doc.Find("table#schedule tbody tr").Each(func(_ int, row *goquery.Selection) {
+		if row.HasClass("thead") {
+			if strings.Contains(row.Text(), "Playoffs") {
+				playoffs = true
+			}
+			return
+		}
+		if g, ok := parseRow(row, s); ok {
+			if playoffs && g.SeasonType != warehouse.SeasonTypePlayIn {
+				g.SeasonType = stats.SeasonTypePlayoffs
+			}
+			games = append(games, g)
+		}
+	})
+	return games, playoffs
+}
+
+// parseRow reads one row of the schedule table.
+func parseRow(row *goquery.Selection, s season.Season) (Game, bool) {
+	cell := func(stat string) *goquery.Selection {
+		return row.Find("[data-stat='" + stat + "']")
+	}
+	code := func(stat string) string {
+		href, _ := cell(stat).Find("a").Attr("href")
+		if m := teamHref.FindStringSubmatch(href); m != nil {
+			return m[1]
+		}
+		return ""
+	}
+	score := func(stat string) *int {
+		n, err := strconv.Atoi(strings.TrimSpace(cell(stat).Text()))
+		if err != nil {
+			return nil
+		}
+		return &n
+	}
+
+	date, err := time.Parse("Mon, Jan 2, 2006", strings.TrimSpace(cell("date_game").Text()))
+	if err != nil {
+		return Game{}, false
+	}
+	g := Game{
+		Season:     s.Key(),
+		SeasonType: stats.SeasonTypeRegular,
+		Date:       date,
+		StartTime:  clock(cell("game_start_time").Text()),
+		Away:       code("visitor_team_name"),
+		Home:       code("home_team_name"),
+		AwayName:   strings.TrimSpace(cell("visitor_team_name").Text()),
+		HomeName:   strings.TrimSpace(cell("home_team_name").Text()),
+		AwayPts:    score("visitor_pts"),
+		HomePts:    score("home_pts"),
+		Overtimes:  strings.TrimSpace(cell("overtimes").Text()),
+	}
+	if g.Away == "" || g.Home == "" {
+		return Game{}, false
+	}
+	if strings.Contains(strings.ToLower(cell("game_remarks").Text()), "play-in") {
+		g.SeasonType = warehouse.SeasonTypePlayIn
+	}
+	g.ID, _ = cell("date_game").Attr("csk")
+	if len(g.ID) != 12 {
+		g.ID = date.Format("20060102") + "0" + g.Home
+	}
+	return g, true
+}
+
+// CreateTable creates the schedule table if it does not exist.
+func CreateTable(db *sql.DB) error {
+	stmts := []string{
+		`CREATE TABLE IF NOT EXISTS schedule (
+			game_id     TEXT PRIMARY KEY,
+			season      TEXT NOT NULL,
+			season_type TEXT NOT NULL,
+			date        DATE NOT NULL,
+			start_time  TEXT NOT NULL,
+			away        TEXT NOT NULL,
+			home        TEXT NOT NULL,
+			away_name   TEXT NOT NULL,
+			home_name   TEXT NOT NULL,
+			away_pts    INTEGER,
+			home_pts    INTEGER,
+			overtimes   TEXT NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_schedule_season ON schedule (season, date)`,
+	}
+	for _, stmt := range stmts {
+		if _, err := db.Exec(stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Store replaces a season's schedule, so postponed games move to their new
+// dates.
+func Store(db *sql.DB, seasonKey string, games []Game) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM schedule WHERE season = $1`, seasonKey); err != nil {
+		return fmt.Errorf("clearing %s: %w", seasonKey, err)
+	}
+	for _, g := range games {
+		_, err := tx.Exec(`INSERT INTO schedule (
+				game_id, season, season_type, date, start_time, away, home,
+				away_name, home_name, away_pts, home_pts, overtimes
+			) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12)
+			ON CONFLICT (game_id) DO NOTHING`,
+			g.ID, g.Season, g.SeasonType, g.Date, g.StartTime, g.Away, g.Home,
+			g.AwayName, g.HomeName, g.AwayPts, g.HomePts, g.Overtimes)
+		if err != nil {
+			return fmt.Errorf("insert failed for %s: %w", g.ID, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// GameQuery narrows Games. Zero fields do not filter.
+type GameQuery struct {
+	Season     string
+	SeasonType string
+	From       time.Time // on or after
+	To         time.Time // before
+	Unplayed   bool      // only games with no score and no stored box score
+}
+
+// Games returns scheduled games in date and start time order.
+func Games(db *sql.DB, q GameQuery) ([]Game, error) {
+	var conds []string
+	var args []interface{}
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return "$" + strconv.Itoa(len(args))
+	}
+	if q.Season != "" {
+		conds = append(conds, "season = "+arg(q.Season))
+	}
+	if q.SeasonType != "" {
+		conds = append(conds, "season_type = "+arg(q.SeasonType))
+	}
+	if !q.From.IsZero() {
+		conds = append(conds, "date >= "+arg(q.From))
+	}
+	if !q.To.IsZero() {
+		conds = append(conds, "date < "+arg(q.To))
+	}
+	if q.Unplayed {
+		conds = append(conds, "(away_pts IS NULL OR home_pts IS NULL)",
+			"NOT EXISTS (SELECT 1 FROM games g WHERE g.game_id = s.game_id)")
+	}
+	where := "TRUE"
+	if len(conds) > 0 {
+		where = strings.Join(conds, " AND ")
+	}
+
+	rows, err := db.Query(`SELECT game_id, season, season_type, date, start_time, away, home,
+			away_name, home_name, away_pts, home_pts, overtimes
+		FROM schedule s
+		WHERE `+where+`
+		ORDER BY date, start_time, game_id`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	var games []Game
+	for rows.Next() {
+		var g Game
+		var awayPts, homePts sql.NullInt64
+		err := rows.Scan(&g.ID, &g.Season, &g.SeasonType, &g.Date, &g.StartTime, &g.Away, &g.Home,
+			&g.AwayName, &g.HomeName, &awayPts, &homePts, &g.Overtimes)
+		if err != nil {
+			return nil, fmt.Errorf("scan failed: %w", err)
+		}
+		if awayPts.Valid && homePts.Valid {
+			a, h := int(awayPts.Int64), int(homePts.Int64)
+			g.AwayPts, g.HomePts = &a, &h
+		}
+		games = append(games, g)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	return games, nil
+}
@@ -0,0 +1,72 @@
+package schedule
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+
+	"github.com/umanchanda/NBA-API/season"
+	"github.com/umanchanda/NBA-API/stats"
+	"github.com/umanchanda/NBA-API/warehouse"
+)
+
+func row(csk, date, away, home, remarks string) string {
+	return `<tr><th data-stat="date_game" csk="` + csk + `"><a>` + date + `</a></th>` +
+		`<td data-stat="game_start_time">7:30p</td>` +
+		`<td data-stat="visitor_team_name"><a href="/teams/` + away + `/2024.html">` + away + `</a></td>` +
+		`<td data-stat="visitor_pts">100</td>` +
+		`<td data-stat="home_team_name"><a href="/teams/` + home + `/2024.html">` + home + `</a></td>` +
+		`<td data-stat="home_pts">110</td>` +
+		`<td data-stat="overtimes"></td>` +
+		`<td data-stat="game_remarks">` + remarks + `</td></tr>`
+}
+
+func month(t *testing.T, rows ...string) *goquery.Document {
+	t.Helper()
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(
+		`<table id="schedule"><tbody>` + strings.Join(rows, "") + `</tbody></table>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+func TestParseMonthSeasonTypes(t *testing.T) {
+	s := season.FromEnd(2024)
+	april := month(t,
+		row("202404140BOS", "Sun, Apr 14, 2024", "WAS", "BOS", ""),
+		`<tr class="thead"><th colspan="10">Playoffs</th></tr>`,
+		row("202404160PHI", "Tue, Apr 16, 2024", "MIA", "PHI", "Play-In Game"),
+		row("202404200BOS", "Sat, Apr 20, 2024", "MIA", "BOS", ""),
+	)
+	games, playoffs := parseMonth(april, s, false)
+	if !playoffs {
+		t.Error("the Playoffs separator was not carried over")
+	}
+	want := []struct{ id, seasonType string }{
+		{"202404140BOS", stats.SeasonTypeRegular},
+		{"202404160PHI", warehouse.SeasonTypePlayIn},
+		{"202404200BOS", stats.SeasonTypePlayoffs},
+	}
+	if len(games) != len(want) {
+		t.Fatalf("got %d games, want %d", len(games), len(want))
+	}
+	for i, w := range want {
+		if games[i].ID != w.id || games[i].SeasonType != w.seasonType {
+			t.Errorf("game %d = %s %s, want %s %s", i, games[i].ID, games[i].SeasonType, w.id, w.seasonType)
+		}
+	}
+	if g := games[0]; g.Away != "WAS" || g.Home != "BOS" || *g.AwayPts != 100 || *g.HomePts != 110 || g.StartTime != "19:30" {
+		t.Errorf("game 0 = %+v", g)
+	}
+
+	// A month after the separator starts in the playoffs.
+	may := month(t,
+		row("202405010MIA", "Wed, May 1, 2024", "BOS", "MIA", ""),
+	)
+	games, _ = parseMonth(may, s, true)
+	if len(games) != 1 || games[0].SeasonType != stats.SeasonTypePlayoffs {
+		t.Errorf("May games = %+v, want one playoff game", games)
+	}
+}
@@ -0,0 +1,158 @@
+package schedule
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+
+	"github.com/umanchanda/NBA-API/franchise"
+	"github.com/umanchanda/NBA-API/season"
+	"github.com/umanchanda/NBA-API/stats"
+)
+
+// TeamGame is one game on a team's schedule, from that team's side.
+// Result, scores and the box score links are set once it has been played.
+type TeamGame struct {
+	GameID     string `json:"game_id"`
+	Date       string `json:"date"`
+	Time       string `json:"time,omitempty"` // Eastern
+	SeasonType string `json:"season_type"`
+	HomeAway   string `json:"home_away"`
+	Opp        string `json:"opp"`
+	OppName    string `json:"opp_name"`
+	Status     string `json:"status"`
+	Result     string `json:"result,omitempty"`
+	TeamPts    *int   `json:"team_pts,omitempty"`
+	OppPts     *int   `json:"opp_pts,omitempty"`
+	Overtimes  string `json:"overtimes,omitempty"`
+	Record     string `json:"record,omitempty"`
+	BoxScore   string `json:"box_score,omitempty"`
+	TeamStats  string `json:"team_stats,omitempty"`
+}
+
+// Record is a win-loss record.
+type Record struct {
+	Wins   int `json:"wins"`
+	Losses int `json:"losses"`
+}
+
+func (r Record) String() string {
+	return strconv.Itoa(r.Wins) + "-" + strconv.Itoa(r.Losses)
+}
+
+// TeamSchedule is the /api/team/{code}/schedule response.
+type TeamSchedule struct {
+	Franchise   string     `json:"franchise"`
+	Team        string     `json:"team"`
+	Name        string     `json:"name"`
+	Season      string     `json:"season"`
+	SeasonLabel string     `json:"season_label"`
+	Record      Record     `json:"record"`
+	Played      []TeamGame `json:"played"`
+	Upcoming    []TeamGame `json:"upcoming"`
+}
+
+const (
+	StatusFinal     = "final"
+	StatusScheduled = "scheduled"
+)
+
+// ForTeam returns a team's schedule for a season: games already played with
+// their results and the running record for each season type, then the games
+// still to come. The code is resolved to the one the franchise used that
+// season.
+func ForTeam(db *sql.DB, code, seasonKey string) (TeamSchedule, error) {
+	f, ok := franchise.Lookup(code)
+	if !ok {
+		return TeamSchedule{}, stats.ErrBadQuery{Err: fmt.Errorf("unknown team %q", code)}
+	}
+	year, _ := strconv.Atoi(seasonKey)
+	era, ok := f.EraFor(year)
+	if !ok {
+		return TeamSchedule{}, stats.ErrBadQuery{Err: fmt.Errorf("%s did not play in %s", f.ID, season.Label(seasonKey))}
+	}
+
+	rows, err := db.Query(`SELECT game_id, season_type, date, start_time, away, home,
+			away_name, home_name, away_pts, home_pts, overtimes
+		FROM schedule
+		WHERE season = $1 AND (away = $2 OR home = $2)
+		ORDER BY date, start_time, game_id`, seasonKey, era.Code)
+	if err != nil {
+		return TeamSchedule{}, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	ts := TeamSchedule{
+		Franchise:   f.ID,
+		Team:        era.Code,
+		Name:        era.Name,
+		Season:      seasonKey,
+		SeasonLabel: season.Label(seasonKey),
+		Played:      []TeamGame{},
+		Upcoming:    []TeamGame{},
+	}
+	records := make(map[string]*Record)
+	for rows.Next() {
+		var g Game
+		var awayPts, homePts sql.NullInt64
+		err := rows.Scan(&g.ID, &g.SeasonType, &g.Date, &g.StartTime, &g.Away, &g.Home,
+			&g.AwayName, &g.HomeName, &awayPts, &homePts, &g.Overtimes)
+		if err != nil {
+			return TeamSchedule{}, fmt.Errorf("scan failed: %w", err)
+		}
+		ts.add(g, awayPts, homePts, records)
+	}
+	if err := rows.Err(); err != nil {
+		return TeamSchedule{}, fmt.Errorf("query failed: %w", err)
+	}
+	if r, ok := records[stats.SeasonTypeRegular]; ok {
+		ts.Record = *r
+	}
+	return ts, nil
+}
+
+// add places g on the schedule from ts.Team's side.
+func (ts *TeamSchedule) add(g Game, awayPts, homePts sql.NullInt64, records map[string]*Record) {
+	tg := TeamGame{
+		GameID:     g.ID,
+		Date:       g.Date.Format("2006-01-02"),
+		Time:       g.StartTime,
+		SeasonType: g.SeasonType,
+		HomeAway:   "home",
+		Opp:        g.Away,
+		OppName:    g.AwayName,
+		Status:     StatusScheduled,
+		Overtimes:  g.Overtimes,
+	}
+	teamPts, oppPts := homePts, awayPts
+	if g.Away == ts.Team {
+		tg.HomeAway, tg.Opp, tg.OppName = "away", g.Home, g.HomeName
+		teamPts, oppPts = awayPts, homePts
+	}
+	if !teamPts.Valid || !oppPts.Valid {
+		ts.Upcoming = append(ts.Upcoming, tg)
+		return
+	}
+
+	t, o := int(teamPts.Int64), int(oppPts.Int64)
+	tg.Status, tg.TeamPts, tg.OppPts = StatusFinal, &t, &o
+	r := records[g.SeasonType]
+	if r == nil {
+		r = &Record{}
+		records[g.SeasonType] = r
+	}
+	if t > o {
+		tg.Result = "W"
+		r.Wins++
+	} else {
+		tg.Result = "L"
+		r.Losses++
+	}
+	tg.Record = r.String()
+
+	// The same links the daily scores page gives each game.
+	date := g.Date.Format("2006/01/02")
+	tg.BoxScore = "/playerstats/" + date + "/" + g.Away + "/" + g.Home
+	tg.TeamStats = "/teamstats/" + date + "/" + g.Away + "/" + g.Home
+	ts.Played = append(ts.Played, tg)
+}
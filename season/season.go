@@ -0,0 +1,177 @@
+package season
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// First and last end years Parse accepts. 1947 is the BAA's first season.
+const (
+	minYear = 1947
+	maxYear = 2100
+)
+
+// Season is an NBA season, which starts in the fall of Start and ends in
+// the spring of End. Seasons are stored under their end year ("2024"),
+// following basketball-reference.
+type Season struct {
+	Start int
+	End   int
+}
+
+// ErrFormat explains which forms Parse accepts.
+var ErrFormat = fmt.Errorf(`season must look like "2023-24", "2023-2024", "23-24", "'24" or "2024" (the year it ends)`)
+
+var (
+	span  = regexp.MustCompile(`^(\d{4})\s*[-/]\s*(\d{2}|\d{4})$`)
+	short = regexp.MustCompile(`^(\d{2})\s*[-/]\s*(\d{2})$`)
+	apos  = regexp.MustCompile(`^['’](\d{2})$`)
+	year  = regexp.MustCompile(`^\d{4}$`)
+)
+
+// century expands a two-digit end year, reading 47-99 as 19xx.
+func century(yy int) int {
+	if yy >= minYear%100 {
+		return 1900 + yy
+	}
+	return 2000 + yy
+}
+
+// FromEnd returns the season ending in year.
+func FromEnd(year int) Season {
+	return Season{Start: year - 1, End: year}
+}
+
+// Parse reads a season written as "2023-24", "2023-2024", "2023/24",
+// "23-24", "'24" or "2024". A bare year is the year the season ends, as on
+// basketball-reference, so "2024" is 2023-24.
+func Parse(s string) (Season, error) {
+	s = strings.TrimSpace(s)
+	var end int
+	switch {
+	case span.MatchString(s):
+		m := span.FindStringSubmatch(s)
+		start, _ := strconv.Atoi(m[1])
+		end, _ = strconv.Atoi(m[2])
+		if len(m[2]) == 2 {
+			end += start / 100 * 100
+			if end <= start {
+				end += 100
+			}
+		}
+		if end != start+1 {
+			return Season{}, fmt.Errorf("%q: a season spans two consecutive years", s)
+		}
+	case short.MatchString(s):
+		m := short.FindStringSubmatch(s)
+		start, _ := strconv.Atoi(m[1])
+		yy, _ := strconv.Atoi(m[2])
+		if (start+1)%100 != yy {
+			return Season{}, fmt.Errorf("%q: a season spans two consecutive years", s)
+		}
+		end = century(yy)
+	case apos.MatchString(s):
+		yy, _ := strconv.Atoi(apos.FindStringSubmatch(s)[1])
+		end = century(yy)
+	case year.MatchString(s):
+		end, _ = strconv.Atoi(s)
+	default:
+		return Season{}, ErrFormat
+	}
+
+	if end < minYear || end > maxYear {
+		return Season{}, fmt.Errorf("%q: no NBA season ends in %d", s, end)
+	}
+	return FromEnd(end), nil
+}
+
+// FromDate returns the season a game on t belongs to. Seasons roll over on
+// August 1st, except that the 2019-20 season ran into October 2020.
+func FromDate(t time.Time) Season {
+	if t.Year() == 2020 && t.Before(time.Date(2020, time.November, 1, 0, 0, 0, 0, time.UTC)) {
+		return FromEnd(2020)
+	}
+	if t.Month() >= time.August {
+		return FromEnd(t.Year() + 1)
+	}
+	return FromEnd(t.Year())
+}
+
+// String renders the season as "2023-24".
+func (s Season) String() string {
+	return fmt.Sprintf("%d-%02d", s.Start, s.End%100)
+}
+
+// Key is the form seasons are stored and looked up under: the end year.
+func (s Season) Key() string {
+	return strconv.Itoa(s.End)
+}
+
+// Games returns how many regular-season games each team played.
+func (s Season) Games() int {
+	switch s.End {
+	case 1999:
+		return 50
+	case 2012:
+		return 66
+	case 2020, 2021:
+		return 72
+	}
+	return 82
+}
+
+func (s Season) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Start int    `json:"start"`
+		End   int    `json:"end"`
+		Label string `json:"label"`
+	}{s.Start, s.End, s.String()})
+}
+
+// Label renders a stored season key ("2024") as "2023-24", returning key
+// unchanged if it is not a season.
+func Label(key string) string {
+	y, err := strconv.Atoi(key)
+	if err != nil {
+		return key
+	}
+	return FromEnd(y).String()
+}
+
+// ParseDate validates a date given as separate year, month and day strings,
+// as in the /scores/{year}/{month}/{day} routes. Month and day may omit the
+// leading zero.
+func ParseDate(year, month, day string) (time.Time, error) {
+	y, errY := strconv.Atoi(year)
+	m, errM := strconv.Atoi(month)
+	d, errD := strconv.Atoi(day)
+	if errY != nil || errM != nil || errD != nil {
+		return time.Time{}, fmt.Errorf("date must be numeric year, month and day")
+	}
+	t := time.Date(y, time.Month(m), d, 0, 0, 0, 0, time.UTC)
+	if t.Year() != y || int(t.Month()) != m || t.Day() != d {
+		return time.Time{}, fmt.Errorf("%s-%s-%s is not a date", year, month, day)
+	}
+	if y < minYear-1 || y > maxYear {
+		return time.Time{}, fmt.Errorf("no NBA games in %d", y)
+	}
+	return t, nil
+}
+
+// ParseDay reads a date written as "2024-02-15" or "20240215".
+func ParseDay(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	for _, layout := range []string{"2006-01-02", "20060102"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			if t.Year() < minYear-1 || t.Year() > maxYear {
+				return time.Time{}, fmt.Errorf("no NBA games in %d", t.Year())
+			}
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf(`date must look like "2024-02-15" or "20240215"`)
+}
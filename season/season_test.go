@@ -0,0 +1,88 @@
+package season
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int // end year, 0 for an error
+	}{
+		{"2023", 2023},
+		{"2022-23", 2023},
+		{"2022-2023", 2023},
+		{"2022/23", 2023},
+		{" 2022 - 23 ", 2023},
+		{"22-23", 2023},
+		{"'23", 2023},
+		{"’99", 1999},
+		{"1999-00", 2000},
+		{"99-00", 2000},
+		{"1947", 1947},
+		{"1946", 0},
+		{"2022-24", 0},
+		{"2022-2022", 0},
+		{"22-24", 0},
+		{"23", 0},
+		{"last year", 0},
+		{"", 0},
+	}
+	for _, tt := range tests {
+		s, err := Parse(tt.in)
+		switch {
+		case tt.want == 0 && err == nil:
+			t.Errorf("Parse(%q) = %v, want an error", tt.in, s)
+		case tt.want != 0 && err != nil:
+			t.Errorf("Parse(%q): %v", tt.in, err)
+		case tt.want != 0 && (s.End != tt.want || s.Start != tt.want-1):
+			t.Errorf("Parse(%q) = %d-%d, want season ending %d", tt.in, s.Start, s.End, tt.want)
+		}
+	}
+}
+
+func TestFromDate(t *testing.T) {
+	tests := []struct {
+		date string
+		want int
+	}{
+		{"2023-06-12", 2023}, // Finals
+		{"2023-07-31", 2023},
+		{"2023-08-01", 2024},
+		{"2023-10-01", 2024},
+		{"2023-10-24", 2024}, // opening night
+		{"2024-01-01", 2024},
+		{"2020-03-11", 2020},
+		{"2020-08-01", 2020}, // the bubble restart
+		{"2020-10-11", 2020}, // the bubble Finals
+		{"2020-11-01", 2021},
+		{"2020-12-22", 2021},
+		{"2021-07-20", 2021},
+		{"2021-10-19", 2022},
+	}
+	for _, tt := range tests {
+		d, _ := time.Parse("2006-01-02", tt.date)
+		if got := FromDate(d); got.End != tt.want {
+			t.Errorf("FromDate(%s) = %v, want the season ending %d", tt.date, got, tt.want)
+		}
+	}
+}
+
+func TestLabel(t *testing.T) {
+	tests := map[string]string{
+		"2024": "2023-24",
+		"2000": "1999-00",
+		"1947": "1946-47",
+		"x":    "x",
+		"":     "",
+	}
+	for key, want := range tests {
+		if got := Label(key); got != want {
+			t.Errorf("Label(%q) = %q, want %q", key, got, want)
+		}
+	}
+	if got := FromEnd(2024).Key(); got != "2024" {
+		t.Errorf("Key() = %q, want 2024", got)
+	}
+}
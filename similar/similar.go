@@ -0,0 +1,254 @@
+package similar
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/umanchanda/NBA-API/season"
+	"github.com/umanchanda/NBA-API/stats"
+)
+
+// minMinutes keeps garbage-time seasons, whose per-36 rates are noise, out
+// of the index.
+const minMinutes = 250
+
+// per36 are the counting stats compared per 36 minutes.
+var per36 = []string{"pts", "orb", "drb", "ast", "stl", "blk", "tov", "pf", "fga", "fg3a", "fta"}
+
+// shooting are compared as-is. Seasons with no attempts get the season mean.
+var shooting = []string{"fg_pct", "fg3_pct", "ft_pct"}
+
+// Weights of the non-stat features relative to a single stat.
+const (
+	ageWeight = 0.5
+	posWeight = 1.0
+)
+
+// ErrNotIndexed is returned for player-seasons that are not in the index.
+var ErrNotIndexed = errors.New("player-season not indexed")
+
+// Season is one indexed player-season.
+type Season struct {
+	PlayerID string `json:"player_id"`
+	Name     string `json:"name"`
+	Season   string `json:"season"`
+	Label    string `json:"season_label"`
+	Team     string `json:"team"`
+	Pos      string `json:"pos"`
+	Age      int    `json:"age"`
+	MP       int    `json:"mp"`
+
+	vec []float64
+}
+
+// Neighbor is a similar player-season. Score is 1 for an identical stat
+// profile and falls toward 0 with distance.
+type Neighbor struct {
+	Season
+	Score float64 `json:"score"`
+}
+
+// Result is the /api/player/{id}/similar response.
+type Result struct {
+	Player  Season     `json:"player"`
+	Similar []Neighbor `json:"similar"`
+}
+
+// Index holds a normalized stat vector for every regular-season
+// player-season with at least minMinutes.
+type Index struct {
+	seasons []Season
+	byKey   map[string]int // player_id + "/" + season
+}
+
+func num(s string) (float64, bool) {
+	f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	return f, err == nil
+}
+
+// position maps a basketball-reference position ("PG", "SF-PF", "G") to
+// the 1 (point guard) to 5 (center) scale, averaging hybrid positions.
+func position(pos string) float64 {
+	scale := map[string]float64{"PG": 1, "SG": 2, "G": 1.5, "SF": 3, "GF": 2.5, "F": 3.5, "PF": 4, "FC": 4.5, "C": 5}
+	var sum float64
+	var n int
+	for _, p := range strings.Split(pos, "-") {
+		if v, ok := scale[strings.TrimSpace(p)]; ok {
+			sum += v
+			n++
+		}
+	}
+	if n == 0 {
+		return 3
+	}
+	return sum / float64(n)
+}
+
+// raw returns the unnormalized features of a row: per-36 rates, shooting
+// percentages (NaN when missing), age and position.
+func raw(r stats.Row, mp float64) []float64 {
+	v := make([]float64, 0, len(per36)+len(shooting)+2)
+	for _, stat := range per36 {
+		n, _ := num(r.Get(stat))
+		v = append(v, n*36/mp)
+	}
+	for _, stat := range shooting {
+		n, ok := num(r.Get(stat))
+		if !ok {
+			n = math.NaN()
+		}
+		v = append(v, n)
+	}
+	age, _ := num(r.Get("age"))
+	return append(v, age, position(r.Get("pos")))
+}
+
+type moments struct{ sum, sq, n float64 }
+
+func (m *moments) add(x float64) {
+	if !math.IsNaN(x) {
+		m.sum += x
+		m.sq += x * x
+		m.n++
+	}
+}
+
+func (m moments) z(x float64) float64 {
+	if m.n == 0 || math.IsNaN(x) {
+		return 0
+	}
+	mean := m.sum / m.n
+	sd := math.Sqrt(m.sq/m.n - mean*mean)
+	if sd == 0 {
+		return 0
+	}
+	return (x - mean) / sd
+}
+
+// Build indexes rows from stats.SeasonRows. Stat features are z-scored
+// within their own season, so a season is measured against its era, while
+// age and position are z-scored across every season.
+func Build(rows []stats.Row) *Index {
+	ix := &Index{byKey: make(map[string]int)}
+	nStats := len(per36) + len(shooting)
+
+	var raws [][]float64
+	bySeason := make(map[string][]moments)
+	global := make([]moments, 2)
+	for _, r := range rows {
+		mp, ok := num(r.Get("mp"))
+		id := r.Get("player_id")
+		if !ok || mp < minMinutes || id == "" {
+			continue
+		}
+		age, _ := num(r.Get("age"))
+		ix.byKey[id+"/"+r.Get("season")] = len(ix.seasons)
+		ix.seasons = append(ix.seasons, Season{
+			PlayerID: id,
+			Name:     r.Get("name"),
+			Season:   r.Get("season"),
+			Label:    season.Label(r.Get("season")),
+			Team:     r.Get("team"),
+			Pos:      r.Get("pos"),
+			Age:      int(age),
+			MP:       int(mp),
+		})
+
+		v := raw(r, mp)
+		raws = append(raws, v)
+		m, ok := bySeason[r.Get("season")]
+		if !ok {
+			m = make([]moments, nStats)
+			bySeason[r.Get("season")] = m
+		}
+		for i := 0; i < nStats; i++ {
+			m[i].add(v[i])
+		}
+		global[0].add(v[nStats])
+		global[1].add(v[nStats+1])
+	}
+
+	for i, v := range raws {
+		m := bySeason[ix.seasons[i].Season]
+		vec := make([]float64, len(v))
+		for j := 0; j < nStats; j++ {
+			vec[j] = m[j].z(v[j])
+		}
+		vec[nStats] = ageWeight * global[0].z(v[nStats])
+		vec[nStats+1] = posWeight * global[1].z(v[nStats+1])
+		ix.seasons[i].vec = vec
+	}
+	return ix
+}
+
+// Len returns the number of indexed player-seasons.
+func (ix *Index) Len() int {
+	return len(ix.seasons)
+}
+
+// Similar returns the k player-seasons closest to playerID's season,
+// leaving out the player's own seasons.
+func (ix *Index) Similar(playerID, season string, k int) (Result, error) {
+	i, ok := ix.byKey[playerID+"/"+season]
+	if !ok {
+		return Result{}, ErrNotIndexed
+	}
+	target := ix.seasons[i]
+
+	neighbors := make([]Neighbor, 0, len(ix.seasons))
+	for _, s := range ix.seasons {
+		if s.PlayerID == playerID {
+			continue
+		}
+		var d float64
+		for j, x := range s.vec {
+			diff := x - target.vec[j]
+			d += diff * diff
+		}
+		neighbors = append(neighbors, Neighbor{Season: s, Score: 1 / (1 + math.Sqrt(d))})
+	}
+	sort.Slice(neighbors, func(a, b int) bool {
+		return neighbors[a].Score > neighbors[b].Score
+	})
+	if len(neighbors) > k {
+		neighbors = neighbors[:k]
+	}
+	for j := range neighbors {
+		neighbors[j].Score = math.Round(neighbors[j].Score*1000) / 1000
+	}
+	return Result{Player: target, Similar: neighbors}, nil
+}
+
+// Store holds the current Index so it can be rebuilt while serving.
+type Store struct {
+	db      *sql.DB
+	current atomic.Pointer[Index]
+}
+
+// NewStore returns an empty Store; call Rebuild to fill it.
+func NewStore(db *sql.DB) *Store {
+	s := &Store{db: db}
+	s.current.Store(&Index{byKey: map[string]int{}})
+	return s
+}
+
+// Index returns the current index. It is safe for concurrent use.
+func (s *Store) Index() *Index {
+	return s.current.Load()
+}
+
+// Rebuild reloads every regular-season row and swaps in a new index.
+func (s *Store) Rebuild() error {
+	rows, err := stats.SeasonRows(s.db, stats.SeasonTypeRegular)
+	if err != nil {
+		return fmt.Errorf("loading seasons: %w", err)
+	}
+	s.current.Store(Build(rows))
+	return nil
+}
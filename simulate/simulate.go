@@ -0,0 +1,371 @@
+// Package simulate plays out the rest of a season many times from Elo
+// ratings to estimate each team's playoff, seeding and title odds.
+package simulate
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"sort"
+	"strconv"
+
+	"github.com/umanchanda/NBA-API/franchise"
+	"github.com/umanchanda/NBA-API/ratings"
+	"github.com/umanchanda/NBA-API/schedule"
+	"github.com/umanchanda/NBA-API/season"
+	"github.com/umanchanda/NBA-API/standings"
+	"github.com/umanchanda/NBA-API/stats"
+)
+
+const (
+	DefaultRuns = 10000
+	MaxRuns     = 100000
+
+	// FirstPlayInSeason is the first season, by end year, with the
+	// play-in tournament for seeds 7 to 10.
+	FirstPlayInSeason = 2021
+)
+
+// Team is one team's simulated outlook. Probabilities are shares of runs;
+// Seeds[i] is the chance of finishing the regular season as seed i+1.
+type Team struct {
+	Franchise   string    `json:"franchise"`
+	Team        string    `json:"team"`
+	Name        string    `json:"name"`
+	Conference  string    `json:"conference"`
+	Elo         float64   `json:"elo"`
+	Wins        int       `json:"wins"`
+	Losses      int       `json:"losses"`
+	ProjWins    float64   `json:"proj_wins"`
+	ProjLosses  float64   `json:"proj_losses"`
+	Seeds       []float64 `json:"seeds"`
+	PlayIn      float64   `json:"play_in"`
+	Playoffs    float64   `json:"playoffs"`
+	SecondRound float64   `json:"second_round"`
+	ConfFinals  float64   `json:"conf_finals"`
+	Finals      float64   `json:"finals"`
+	Title       float64   `json:"title"`
+}
+
+// Simulation is the /api/simulate response. Seed reproduces it.
+type Simulation struct {
+	Season      string `json:"season"`
+	SeasonLabel string `json:"season_label"`
+	Runs        int    `json:"runs"`
+	Seed        uint64 `json:"seed"`
+	Played      int    `json:"played"`
+	Remaining   int    `json:"remaining"`
+	Teams       []Team `json:"teams"`
+}
+
+// Query describes a /api/simulate request.
+type Query struct {
+	Season string
+	Runs   int
+	Seed   uint64
+}
+
+// tally counts one team's outcomes over the runs.
+type tally struct {
+	wins                                   int
+	seeds                                  []int
+	playIn, playoffs, second, conf, finals int
+	titles                                 int
+}
+
+// game is a remaining game between two franchises.
+type game struct {
+	away, home string
+	homeProb   float64
+}
+
+// sim is the state shared by every run.
+type sim struct {
+	rng    *rand.Rand
+	elo    map[string]float64
+	year   int
+	counts map[string]*tally
+}
+
+// Run simulates the rest of q.Season q.Runs times. Every stored result of
+// the season counts as played; the remaining regular season games come from
+// the schedule. Each game is won with its pregame Elo probability, and
+// ratings stay fixed through a run. Simulated games count as one-point wins
+// for the point differential tiebreaker.
+func Run(db *sql.DB, q Query) (Simulation, error) {
+	if q.Runs < 1 || q.Runs > MaxRuns {
+		return Simulation{}, stats.ErrBadQuery{Err: fmt.Errorf("runs must be between 1 and %d", MaxRuns)}
+	}
+	year, _ := strconv.Atoi(q.Season)
+	tb, elo, remaining, err := load(db, q.Season)
+	if err != nil {
+		return Simulation{}, err
+	}
+	counts := simulate(tb, elo, remaining, q.Runs, q.Seed)
+	seeds := tb.Seeds()
+
+	out := Simulation{
+		Season:      q.Season,
+		SeasonLabel: season.Label(q.Season),
+		Runs:        q.Runs,
+		Seed:        q.Seed,
+		Remaining:   len(remaining),
+	}
+	for _, conf := range seeds {
+		for _, id := range conf {
+			w, l := tb.Record(id)
+			out.Played += w + l
+		}
+	}
+	out.Played /= 2
+	runs := float64(q.Runs)
+	share := func(n int) float64 { return math.Round(float64(n)/runs*1000) / 1000 }
+	for _, conf := range []string{franchise.East, franchise.West} {
+		for _, id := range seeds[conf] {
+			f, _ := franchise.Lookup(id)
+			era, _ := f.EraFor(year)
+			c := counts[id]
+			w, l := tb.Record(id)
+			games := float64(w + l + gamesLeft(remaining, id))
+			t := Team{
+				Franchise:   id,
+				Team:        era.Code,
+				Name:        era.Name,
+				Conference:  conf,
+				Elo:         math.Round(elo[id]*10) / 10,
+				Wins:        w,
+				Losses:      l,
+				ProjWins:    math.Round(float64(c.wins)/runs*10) / 10,
+				PlayIn:      share(c.playIn),
+				Playoffs:    share(c.playoffs),
+				SecondRound: share(c.second),
+				ConfFinals:  share(c.conf),
+				Finals:      share(c.finals),
+				Title:       share(c.titles),
+			}
+			t.ProjLosses = math.Round((games-float64(c.wins)/runs)*10) / 10
+			for _, n := range c.seeds {
+				t.Seeds = append(t.Seeds, share(n))
+			}
+			out.Teams = append(out.Teams, t)
+		}
+	}
+	sort.SliceStable(out.Teams, func(i, j int) bool {
+		a, b := out.Teams[i], out.Teams[j]
+		if a.Title != b.Title {
+			return a.Title > b.Title
+		}
+		return a.Playoffs > b.Playoffs
+	})
+	return out, nil
+}
+
+// load reads a season's standings so far, every team's current Elo and the
+// regular season games left to play.
+func load(db *sql.DB, seasonKey string) (*standings.Table, map[string]float64, []game, error) {
+	year, _ := strconv.Atoi(seasonKey)
+	tb, err := standings.NewTable(year)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	results, err := schedule.Results(db, schedule.ResultQuery{ToSeason: seasonKey})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	e := ratings.NewElo()
+	for _, r := range results {
+		e.Play(r)
+		if r.Season == seasonKey && r.SeasonType == stats.SeasonTypeRegular {
+			tb.Add(r)
+		}
+	}
+	e.Season(seasonKey)
+
+	scheduled, err := schedule.Games(db, schedule.GameQuery{
+		Season:     seasonKey,
+		SeasonType: stats.SeasonTypeRegular,
+		Unplayed:   true,
+	})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	elo := make(map[string]float64)
+	for _, conf := range tb.Seeds() {
+		for _, id := range conf {
+			elo[id] = e.Rating(id)
+		}
+	}
+	var remaining []game
+	for _, g := range scheduled {
+		away, okA := franchise.Lookup(g.Away)
+		home, okH := franchise.Lookup(g.Home)
+		if _, ok := elo[away.ID]; !okA || !ok {
+			continue
+		}
+		if _, ok := elo[home.ID]; !okH || !ok {
+			continue
+		}
+		remaining = append(remaining, game{away.ID, home.ID, ratings.WinProbability(elo[home.ID], elo[away.ID])})
+	}
+	return tb, elo, remaining, nil
+}
+
+// simulate plays the rest of the season in tb runs times and returns each
+// franchise's tally. The same seed always gives the same tallies.
+func simulate(tb *standings.Table, elo map[string]float64, remaining []game, runs int, seed uint64) map[string]*tally {
+	s := &sim{
+		rng:    rand.New(rand.NewPCG(seed, seed)),
+		elo:    elo,
+		year:   tb.Year(),
+		counts: make(map[string]*tally),
+	}
+	for _, conf := range tb.Seeds() {
+		for _, id := range conf {
+			s.counts[id] = &tally{seeds: make([]int, len(conf))}
+		}
+	}
+	for i := 0; i < runs; i++ {
+		s.run(tb.Clone(), remaining)
+	}
+	return s.counts
+}
+
+func gamesLeft(remaining []game, id string) int {
+	n := 0
+	for _, g := range remaining {
+		if g.away == id || g.home == id {
+			n++
+		}
+	}
+	return n
+}
+
+// run plays one season to a champion.
+func (s *sim) run(tb *standings.Table, remaining []game) {
+	for _, g := range remaining {
+		if s.rng.Float64() < g.homeProb {
+			tb.AddGame(g.away, g.home, 0, 1)
+		} else {
+			tb.AddGame(g.away, g.home, 1, 0)
+		}
+	}
+	seeds := tb.Seeds()
+	for _, conf := range seeds {
+		for i, id := range conf {
+			c := s.counts[id]
+			w, _ := tb.Record(id)
+			c.wins += w
+			c.seeds[i]++
+		}
+	}
+
+	var champs []string
+	for _, conf := range []string{franchise.East, franchise.West} {
+		field := s.field(seeds[conf])
+		for _, id := range field {
+			s.counts[id].playoffs++
+		}
+		// Seeds 1-8, 4-5, 3-6 and 2-7 meet in the first round, and the
+		// winners of the first two and last two meet in the second.
+		round := []string{field[0], field[7], field[3], field[4], field[2], field[5], field[1], field[6]}
+		for len(round) > 1 {
+			var next []string
+			for i := 0; i < len(round); i += 2 {
+				next = append(next, s.series(round[i], round[i+1], seeds[conf]))
+			}
+			round = next
+			for _, id := range round {
+				switch len(round) {
+				case 4:
+					s.counts[id].second++
+				case 2:
+					s.counts[id].conf++
+				case 1:
+					s.counts[id].finals++
+				}
+			}
+		}
+		champs = append(champs, round[0])
+	}
+
+	// Home court in the Finals goes to the better record.
+	east, west := champs[0], champs[1]
+	ew, _ := tb.Record(east)
+	ww, _ := tb.Record(west)
+	high, low := east, west
+	if ww > ew || (ww == ew && s.elo[west] > s.elo[east]) {
+		high, low = west, east
+	}
+	s.counts[s.bestOfSeven(high, low)].titles++
+}
+
+// field returns a conference's eight playoff teams in seed order, playing
+// the play-in for seeds 7 and 8 in seasons that have one.
+func (s *sim) field(seeds []string) []string {
+	if s.year < FirstPlayInSeason {
+		return seeds[:8]
+	}
+	for _, id := range seeds[6:10] {
+		s.counts[id].playIn++
+	}
+	field := append([]string(nil), seeds[:6]...)
+	seven, loser := seeds[6], seeds[7]
+	if !s.win(seeds[6], seeds[7]) {
+		seven, loser = seeds[7], seeds[6]
+	}
+	nine := seeds[8]
+	if !s.win(seeds[8], seeds[9]) {
+		nine = seeds[9]
+	}
+	eight := loser
+	if !s.win(loser, nine) {
+		eight = nine
+	}
+	return append(field, seven, eight)
+}
+
+// series plays a best-of-seven between two teams of one conference, the
+// better seed at home in games 1, 2, 5 and 7, and returns the winner.
+func (s *sim) series(a, b string, seeds []string) string {
+	for _, id := range seeds {
+		if id == a {
+			return s.bestOfSeven(a, b)
+		}
+		if id == b {
+			return s.bestOfSeven(b, a)
+		}
+	}
+	return s.bestOfSeven(a, b)
+}
+
+// bestOfSeven plays a series with high holding home court and returns the
+// winner.
+func (s *sim) bestOfSeven(high, low string) string {
+	var wh, wl int
+	for g := 1; wh < 4 && wl < 4; g++ {
+		var highWon bool
+		switch g {
+		case 1, 2, 5, 7:
+			highWon = s.win(high, low)
+		default:
+			highWon = !s.win(low, high)
+		}
+		if highWon {
+			wh++
+		} else {
+			wl++
+		}
+	}
+	if wh == 4 {
+		return high
+	}
+	return low
+}
+
+// win plays one game and reports whether the home team won.
+func (s *sim) win(home, away string) bool {
+	return s.rng.Float64() < ratings.WinProbability(s.elo[home], s.elo[away])
+}
@@ -0,0 +1,118 @@
+package simulate
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/umanchanda/NBA-API/franchise"
+	"github.com/umanchanda/NBA-API/ratings"
+	"github.com/umanchanda/NBA-API/standings"
+)
+
+// fixture returns a 2023-24 table with a few games played, ratings spread
+// across the league and two games left for every team.
+func fixture(t *testing.T) (*standings.Table, map[string]float64, []game) {
+	t.Helper()
+	tb, err := standings.NewTable(2024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, conf := range tb.Seeds() {
+		ids = append(ids, conf...)
+	}
+	if len(ids) != 30 {
+		t.Fatalf("got %d teams, want 30", len(ids))
+	}
+	elo := make(map[string]float64)
+	for i, id := range ids {
+		elo[id] = 1350 + 10*float64(i)
+	}
+	var remaining []game
+	for i, id := range ids {
+		opp := ids[(i+1)%len(ids)]
+		tb.AddGame(opp, id, 100, 100+i%7-3)
+		remaining = append(remaining,
+			game{away: id, home: opp, homeProb: ratings.WinProbability(elo[opp], elo[id])},
+			game{away: opp, home: id, homeProb: ratings.WinProbability(elo[id], elo[opp])},
+		)
+	}
+	return tb, elo, remaining
+}
+
+func TestSimulateSeedIsDeterministic(t *testing.T) {
+	tb, elo, remaining := fixture(t)
+	a := simulate(tb, elo, remaining, 200, 42)
+	b := simulate(tb, elo, remaining, 200, 42)
+	if !reflect.DeepEqual(a, b) {
+		t.Error("the same seed gave different results")
+	}
+	if c := simulate(tb, elo, remaining, 200, 43); reflect.DeepEqual(a, c) {
+		t.Error("a different seed gave the same results")
+	}
+}
+
+func TestSimulateInvariants(t *testing.T) {
+	const runs = 500
+	tb, elo, remaining := fixture(t)
+	before, _ := tb.Record(tb.Seeds()[franchise.East][0])
+	counts := simulate(tb, elo, remaining, runs, 7)
+	if after, _ := tb.Record(tb.Seeds()[franchise.East][0]); after != before {
+		t.Error("simulate changed the table it was given")
+	}
+
+	var titles, finals, playoffs, playIn int
+	seedTotals := make(map[int]int)
+	for id, c := range counts {
+		n := 0
+		for i, k := range c.seeds {
+			n += k
+			seedTotals[i] += k
+		}
+		if n != runs {
+			t.Errorf("%s seed probabilities sum to %d/%d", id, n, runs)
+		}
+		if c.titles > c.finals || c.finals > c.conf || c.conf > c.second || c.second > c.playoffs {
+			t.Errorf("%s rounds are not nested: %+v", id, c)
+		}
+		titles += c.titles
+		finals += c.finals
+		playoffs += c.playoffs
+		playIn += c.playIn
+	}
+	if titles != runs {
+		t.Errorf("title odds sum to %d/%d", titles, runs)
+	}
+	if finals != 2*runs {
+		t.Errorf("finals odds sum to %d/%d", finals, 2*runs)
+	}
+	if playoffs != 16*runs {
+		t.Errorf("playoff odds sum to %d/%d", playoffs, 16*runs)
+	}
+	if playIn != 8*runs {
+		t.Errorf("play-in odds sum to %d/%d", playIn, 8*runs)
+	}
+	for i, n := range seedTotals {
+		if n != 2*runs {
+			t.Errorf("seed %d given out %d times, want %d", i+1, n, 2*runs)
+		}
+	}
+}
+
+func TestSimulateBeforePlayIn(t *testing.T) {
+	tb, err := standings.NewTable(2019)
+	if err != nil {
+		t.Fatal(err)
+	}
+	elo := make(map[string]float64)
+	for _, conf := range tb.Seeds() {
+		for _, id := range conf {
+			elo[id] = ratings.InitialElo
+		}
+	}
+	for id, c := range simulate(tb, elo, nil, 50, 1) {
+		if c.playIn != 0 {
+			t.Errorf("%s played in a play-in before %d", id, FirstPlayInSeason)
+		}
+	}
+}
@@ -0,0 +1,486 @@
+// Package standings ranks the teams of each conference and division from
+// stored regular season results.
+package standings
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/umanchanda/NBA-API/franchise"
+	"github.com/umanchanda/NBA-API/schedule"
+	"github.com/umanchanda/NBA-API/season"
+	"github.com/umanchanda/NBA-API/stats"
+)
+
+// Team is one team's line in the standings. Seed is its place in the
+// conference. Tiebreaker names the rule that placed it among teams with the
+// same record, if any.
+type Team struct {
+	Seed           int     `json:"seed"`
+	DivisionRank   int     `json:"division_rank"`
+	Franchise      string  `json:"franchise"`
+	Team           string  `json:"team"`
+	Name           string  `json:"name"`
+	Conference     string  `json:"conference"`
+	Division       string  `json:"division"`
+	Wins           int     `json:"wins"`
+	Losses         int     `json:"losses"`
+	Pct            float64 `json:"pct"`
+	GB             float64 `json:"gb"`
+	DivisionGB     float64 `json:"division_gb"`
+	DivisionLeader bool    `json:"division_leader"`
+	Home           string  `json:"home"`
+	Road           string  `json:"road"`
+	DivisionRecord string  `json:"division_record"`
+	ConfRecord     string  `json:"conference_record"`
+	Last10         string  `json:"last_10"`
+	Streak         string  `json:"streak"`
+	PointDiff      int     `json:"point_diff"`
+	Tiebreaker     string  `json:"tiebreaker,omitempty"`
+}
+
+// Conference is one conference's standings, in seed order.
+type Conference struct {
+	Name  string `json:"name"`
+	Teams []Team `json:"teams"`
+}
+
+// Division is one division's standings.
+type Division struct {
+	Name       string `json:"name"`
+	Conference string `json:"conference"`
+	Teams      []Team `json:"teams"`
+}
+
+// Standings is the /api/standings response.
+type Standings struct {
+	Date        string       `json:"date"`
+	Season      string       `json:"season"`
+	SeasonLabel string       `json:"season_label"`
+	Games       int          `json:"games"`
+	Conferences []Conference `json:"conferences"`
+	Divisions   []Division   `json:"divisions"`
+}
+
+// The NBA tiebreakers the data allows. Record against playoff teams is
+// skipped: it depends on the standings being computed.
+const (
+	TiebreakHeadToHead = "head_to_head"
+	TiebreakDivLeader  = "division_leader"
+	TiebreakDivRecord  = "division_record"
+	TiebreakConfRecord = "conference_record"
+	TiebreakPointDiff  = "point_differential"
+)
+
+// team accumulates one team's results.
+type team struct {
+	Team
+	won, lost  int
+	home, road schedule.Record
+	div, conf  schedule.Record
+	vs         map[string]*schedule.Record // by opponent franchise id
+	results    []bool                      // wins, oldest first
+	pointsDiff int
+}
+
+func tally(won bool, r *schedule.Record) {
+	if won {
+		r.Wins++
+	} else {
+		r.Losses++
+	}
+}
+
+// sameRecord reports whether a and b have the same winning percentage. A
+// team with no games counts as .500.
+func sameRecord(a, b *team) bool {
+	return pct(a.won, a.lost) == pct(b.won, b.lost)
+}
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func pct(w, l int) float64 {
+	if w+l == 0 {
+		return 0.5
+	}
+	return float64(w) / float64(w+l)
+}
+
+// Table accumulates a season's regular season results and ranks them. The
+// simulator copies one per run and adds simulated games.
+type Table struct {
+	teams map[string]*team // by franchise id
+	games int
+	year  int
+}
+
+// NewTable returns an empty table for the season ending in year, which must
+// be FirstAlignedSeason or later.
+func NewTable(year int) (*Table, error) {
+	if year < franchise.FirstAlignedSeason {
+		return nil, stats.ErrBadQuery{Err: fmt.Errorf("standings start with %s, the first season of the current divisions", season.Label(strconv.Itoa(franchise.FirstAlignedSeason)))}
+	}
+	tb := &Table{teams: make(map[string]*team), year: year}
+	for _, f := range franchise.All() {
+		conf, div, ok := f.Alignment(year)
+		if !ok {
+			continue
+		}
+		era, _ := f.EraFor(year)
+		tb.teams[f.ID] = &team{
+			Team: Team{Franchise: f.ID, Team: era.Code, Name: era.Name, Conference: conf, Division: div},
+			vs:   make(map[string]*schedule.Record),
+		}
+	}
+	return tb, nil
+}
+
+// Add counts a result. It is ignored if either code is not a team in the
+// table.
+func (tb *Table) Add(r schedule.Result) {
+	away, okA := franchise.Lookup(r.Away)
+	home, okH := franchise.Lookup(r.Home)
+	if okA && okH {
+		tb.AddGame(away.ID, home.ID, r.AwayPts, r.HomePts)
+	}
+}
+
+// AddGame counts a game between two franchises.
+func (tb *Table) AddGame(away, home string, awayPts, homePts int) {
+	a, h := tb.teams[away], tb.teams[home]
+	if a == nil || h == nil {
+		return
+	}
+	tb.games++
+	homeWon := homePts > awayPts
+	a.add(h, !homeWon, awayPts-homePts, &a.road)
+	h.add(a, homeWon, homePts-awayPts, &h.home)
+}
+
+// Clone returns an independent copy of the table.
+func (tb *Table) Clone() *Table {
+	c := &Table{teams: make(map[string]*team, len(tb.teams)), games: tb.games, year: tb.year}
+	for id, t := range tb.teams {
+		ct := *t
+		ct.vs = make(map[string]*schedule.Record, len(t.vs))
+		for o, r := range t.vs {
+			rc := *r
+			ct.vs[o] = &rc
+		}
+		ct.results = append([]bool(nil), t.results...)
+		c.teams[id] = &ct
+	}
+	return c
+}
+
+// Year returns the end year of the table's season.
+func (tb *Table) Year() int {
+	return tb.year
+}
+
+// Record returns a franchise's wins and losses.
+func (tb *Table) Record(id string) (wins, losses int) {
+	if t := tb.teams[id]; t != nil {
+		return t.won, t.lost
+	}
+	return 0, 0
+}
+
+// Seeds returns each conference's franchise ids, best first.
+func (tb *Table) Seeds() map[string][]string {
+	byConf, _ := tb.rank()
+	seeds := make(map[string][]string, len(byConf))
+	for conf, group := range byConf {
+		for _, t := range group {
+			seeds[conf] = append(seeds[conf], t.Franchise)
+		}
+	}
+	return seeds
+}
+
+// rank orders every conference and division.
+func (tb *Table) rank() (byConf, byDiv map[string][]*team) {
+	byDiv = make(map[string][]*team)
+	byConf = make(map[string][]*team)
+	for _, t := range tb.teams {
+		t.DivisionLeader, t.Tiebreaker = false, ""
+		byDiv[t.Division] = append(byDiv[t.Division], t)
+		byConf[t.Conference] = append(byConf[t.Conference], t)
+	}
+	// Division leaders are settled first, since leading a division is
+	// itself a tiebreaker for the conference seeds.
+	for _, group := range byDiv {
+		rank(group)
+		group[0].DivisionLeader = true
+	}
+	// Division ranks break ties by their own rules, so the tiebreaker shown
+	// is the conference's.
+	for _, t := range tb.teams {
+		t.Tiebreaker = ""
+	}
+	for _, group := range byConf {
+		rank(group)
+	}
+	return byConf, byDiv
+}
+
+// On computes the standings after every regular season game played on or
+// before date.
+func On(db *sql.DB, date time.Time) (Standings, error) {
+	date = time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	s := season.FromDate(date)
+	year, _ := strconv.Atoi(s.Key())
+	tb, err := NewTable(year)
+	if err != nil {
+		return Standings{}, err
+	}
+
+	results, err := schedule.Results(db, schedule.ResultQuery{
+		FromSeason: s.Key(),
+		ToSeason:   s.Key(),
+		SeasonType: stats.SeasonTypeRegular,
+		Before:     date.AddDate(0, 0, 1),
+	})
+	if err != nil {
+		return Standings{}, err
+	}
+	for _, r := range results {
+		tb.Add(r)
+	}
+
+	st := Standings{
+		Date:        date.Format("2006-01-02"),
+		Season:      s.Key(),
+		SeasonLabel: s.String(),
+		Games:       tb.games,
+	}
+	byConf, byDiv := tb.rank()
+	for _, group := range byConf {
+		for i, t := range group {
+			t.Seed = i + 1
+			t.GB = gamesBehind(group[0], t)
+		}
+	}
+	divNames := make([]string, 0, len(byDiv))
+	for name, group := range byDiv {
+		for i, t := range group {
+			t.DivisionRank = i + 1
+			t.DivisionGB = gamesBehind(group[0], t)
+		}
+		divNames = append(divNames, name)
+	}
+	for _, conf := range []string{franchise.East, franchise.West} {
+		c := Conference{Name: conf}
+		for _, t := range byConf[conf] {
+			c.Teams = append(c.Teams, t.finish())
+		}
+		st.Conferences = append(st.Conferences, c)
+	}
+
+	sort.Slice(divNames, func(i, j int) bool {
+		a, b := byDiv[divNames[i]][0], byDiv[divNames[j]][0]
+		if a.Conference != b.Conference {
+			return a.Conference == franchise.East
+		}
+		return divNames[i] < divNames[j]
+	})
+	for _, name := range divNames {
+		d := Division{Name: name, Conference: byDiv[name][0].Conference}
+		for _, t := range byDiv[name] {
+			d.Teams = append(d.Teams, t.finish())
+		}
+		st.Divisions = append(st.Divisions, d)
+	}
+	return st, nil
+}
+
+// add counts one game against opp. split is the home or road record.
+func (t *team) add(opp *team, won bool, margin int, split *schedule.Record) {
+	if won {
+		t.won++
+	} else {
+		t.lost++
+	}
+	t.pointsDiff += margin
+	t.results = append(t.results, won)
+	tally(won, split)
+	if opp.Conference == t.Conference {
+		tally(won, &t.conf)
+		if opp.Division == t.Division {
+			tally(won, &t.div)
+		}
+	}
+	r := t.vs[opp.Franchise]
+	if r == nil {
+		r = &schedule.Record{}
+		t.vs[opp.Franchise] = r
+	}
+	tally(won, r)
+}
+
+// finish fills in the reported fields.
+func (t *team) finish() Team {
+	out := t.Team
+	out.Wins, out.Losses = t.won, t.lost
+	out.Pct = math.Round(pct(t.won, t.lost)*1000) / 1000
+	if t.won+t.lost == 0 {
+		out.Pct = 0
+	}
+	out.Home, out.Road = t.home.String(), t.road.String()
+	out.DivisionRecord, out.ConfRecord = t.div.String(), t.conf.String()
+	out.PointDiff = t.pointsDiff
+
+	var last schedule.Record
+	for i := len(t.results) - 1; i >= 0 && i >= len(t.results)-10; i-- {
+		tally(t.results[i], &last)
+	}
+	out.Last10 = last.String()
+
+	if n := len(t.results); n > 0 {
+		streak := 1
+		for streak < n && t.results[n-1-streak] == t.results[n-1] {
+			streak++
+		}
+		if t.results[n-1] {
+			out.Streak = "W" + strconv.Itoa(streak)
+		} else {
+			out.Streak = "L" + strconv.Itoa(streak)
+		}
+	}
+	return out
+}
+
+func gamesBehind(leader, t *team) float64 {
+	return float64((leader.won-t.won)+(t.lost-leader.lost)) / 2
+}
+
+// rank orders group best first, breaking ties between teams with the same
+// winning percentage.
+func rank(group []*team) {
+	sort.SliceStable(group, func(i, j int) bool {
+		a, b := group[i], group[j]
+		if sameRecord(a, b) {
+			return a.Franchise < b.Franchise
+		}
+		return pct(a.won, a.lost) > pct(b.won, b.lost)
+	})
+	for i := 0; i < len(group); {
+		j := i + 1
+		for j < len(group) {
+			if !sameRecord(group[i], group[j]) {
+				break
+			}
+			j++
+		}
+		copy(group[i:j], breakTie(group[i:j]))
+		i = j
+	}
+}
+
+// criterion is one tiebreaker. applies reports whether it can be used for
+// the tied teams; value scores a team among them, higher is better.
+type criterion struct {
+	name    string
+	applies func(tied []*team) bool
+	value   func(t *team, tied []*team) float64
+}
+
+var (
+	headToHead = criterion{TiebreakHeadToHead, always, func(t *team, tied []*team) float64 {
+		var r schedule.Record
+		for _, o := range tied {
+			if v := t.vs[o.Franchise]; v != nil && o != t {
+				r.Wins += v.Wins
+				r.Losses += v.Losses
+			}
+		}
+		return pct(r.Wins, r.Losses)
+	}}
+	divLeader = criterion{TiebreakDivLeader, func(tied []*team) bool { return !sameDivision(tied) }, func(t *team, _ []*team) float64 {
+		return float64(boolInt(t.DivisionLeader))
+	}}
+	divRecord = criterion{TiebreakDivRecord, sameDivision, func(t *team, _ []*team) float64 {
+		return pct(t.div.Wins, t.div.Losses)
+	}}
+	confRecord = criterion{TiebreakConfRecord, sameConference, func(t *team, _ []*team) float64 {
+		return pct(t.conf.Wins, t.conf.Losses)
+	}}
+	pointDiff = criterion{TiebreakPointDiff, always, func(t *team, _ []*team) float64 {
+		return float64(t.pointsDiff)
+	}}
+
+	// Two teams go to head-to-head first; three or more first separate
+	// division leaders from the rest.
+	twoTeam   = []criterion{headToHead, divLeader, divRecord, confRecord, pointDiff}
+	multiTeam = []criterion{divLeader, headToHead, divRecord, confRecord, pointDiff}
+)
+
+func always([]*team) bool { return true }
+
+func sameDivision(tied []*team) bool {
+	for _, t := range tied[1:] {
+		if t.Division != tied[0].Division {
+			return false
+		}
+	}
+	return true
+}
+
+func sameConference(tied []*team) bool {
+	for _, t := range tied[1:] {
+		if t.Conference != tied[0].Conference {
+			return false
+		}
+	}
+	return true
+}
+
+// breakTie orders teams with the same winning percentage. The first
+// criterion that separates them splits them into groups, and teams still
+// tied within a group start over, as the NBA's rules do.
+func breakTie(tied []*team) []*team {
+	if len(tied) < 2 {
+		return tied
+	}
+	criteria := multiTeam
+	if len(tied) == 2 {
+		criteria = twoTeam
+	}
+	for _, c := range criteria {
+		if !c.applies(tied) {
+			continue
+		}
+		values := make(map[*team]float64, len(tied))
+		for _, t := range tied {
+			values[t] = c.value(t, tied)
+		}
+		order := append([]*team(nil), tied...)
+		sort.SliceStable(order, func(i, j int) bool { return values[order[i]] > values[order[j]] })
+		if values[order[0]] == values[order[len(order)-1]] {
+			continue
+		}
+		for _, t := range order {
+			t.Tiebreaker = c.name
+		}
+		var out []*team
+		for i := 0; i < len(order); {
+			j := i + 1
+			for j < len(order) && values[order[j]] == values[order[i]] {
+				j++
+			}
+			out = append(out, breakTie(order[i:j])...)
+			i = j
+		}
+		return out
+	}
+	return tied
+}
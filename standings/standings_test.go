@@ -0,0 +1,154 @@
+package standings
+
+import (
+	"testing"
+
+	"github.com/umanchanda/NBA-API/franchise"
+	"github.com/umanchanda/NBA-API/schedule"
+)
+
+// tie builds teams with the same 41-41 record, all in the East.
+func tie(ids map[string]string) map[string]*team {
+	teams := make(map[string]*team)
+	for id, div := range ids {
+		teams[id] = &team{
+			Team: Team{Franchise: id, Conference: franchise.East, Division: div},
+			won:  41, lost: 41,
+			vs: make(map[string]*schedule.Record),
+		}
+	}
+	return teams
+}
+
+// series records a's wins and losses against b.
+func series(teams map[string]*team, a, b string, wins, losses int) {
+	ra, rb := teams[a].vs[b], teams[b].vs[a]
+	if ra == nil {
+		ra, rb = &schedule.Record{}, &schedule.Record{}
+		teams[a].vs[b], teams[b].vs[a] = ra, rb
+	}
+	ra.Wins, ra.Losses = ra.Wins+wins, ra.Losses+losses
+	rb.Wins, rb.Losses = rb.Wins+losses, rb.Losses+wins
+}
+
+func order(teams map[string]*team, ids ...string) []*team {
+	out := make([]*team, len(ids))
+	for i, id := range ids {
+		out[i] = teams[id]
+	}
+	return out
+}
+
+func check(t *testing.T, got []*team, want []string, tiebreakers []string) {
+	t.Helper()
+	for i, tm := range got {
+		if tm.Franchise != want[i] || tm.Tiebreaker != tiebreakers[i] {
+			var g []string
+			for _, tm := range got {
+				g = append(g, tm.Franchise+" ("+tm.Tiebreaker+")")
+			}
+			t.Fatalf("order = %v, want %v with %v", g, want, tiebreakers)
+		}
+	}
+}
+
+func TestBreakTie(t *testing.T) {
+	tests := []struct {
+		name        string
+		divisions   map[string]string
+		setup       func(map[string]*team)
+		in          []string
+		want        []string
+		tiebreakers []string
+	}{
+		{
+			name:      "two teams on head-to-head",
+			divisions: map[string]string{"BOS": "Atlantic", "MIL": "Central"},
+			setup: func(tm map[string]*team) {
+				series(tm, "MIL", "BOS", 3, 1)
+				// Leading the division comes second for two teams.
+				tm["BOS"].DivisionLeader = true
+			},
+			in:          []string{"BOS", "MIL"},
+			want:        []string{"MIL", "BOS"},
+			tiebreakers: []string{TiebreakHeadToHead, TiebreakHeadToHead},
+		},
+		{
+			name:      "two teams split, same division",
+			divisions: map[string]string{"BOS": "Atlantic", "NYK": "Atlantic"},
+			setup: func(tm map[string]*team) {
+				series(tm, "BOS", "NYK", 2, 2)
+				tm["BOS"].div = schedule.Record{Wins: 8, Losses: 8}
+				tm["NYK"].div = schedule.Record{Wins: 10, Losses: 6}
+			},
+			in:          []string{"BOS", "NYK"},
+			want:        []string{"NYK", "BOS"},
+			tiebreakers: []string{TiebreakDivRecord, TiebreakDivRecord},
+		},
+		{
+			name:      "three teams, a division leader first",
+			divisions: map[string]string{"BOS": "Atlantic", "MIL": "Central", "MIA": "Southeast"},
+			setup: func(tm map[string]*team) {
+				tm["MIA"].DivisionLeader = true
+				series(tm, "BOS", "MIA", 0, 4)
+				series(tm, "MIL", "MIA", 0, 3)
+				// Between the two left, head-to-head decides.
+				series(tm, "BOS", "MIL", 3, 1)
+			},
+			in:          []string{"MIL", "BOS", "MIA"},
+			want:        []string{"MIA", "BOS", "MIL"},
+			tiebreakers: []string{TiebreakDivLeader, TiebreakHeadToHead, TiebreakHeadToHead},
+		},
+		{
+			name: "four teams restart after a partial split",
+			divisions: map[string]string{
+				"BOS": "Atlantic", "NYK": "Atlantic", "PHI": "Atlantic", "BRK": "Atlantic",
+			},
+			setup: func(tm map[string]*team) {
+				// Among all four BOS is 6-0, NYK and PHI 4-4 and 5-5, BRK 1-7.
+				series(tm, "BOS", "NYK", 2, 0)
+				series(tm, "BOS", "PHI", 2, 0)
+				series(tm, "BOS", "BRK", 2, 0)
+				series(tm, "NYK", "PHI", 3, 1)
+				series(tm, "NYK", "BRK", 1, 1)
+				series(tm, "PHI", "BRK", 4, 0)
+				// NYK and PHI start over with the two-team rules, so their
+				// own head-to-head decides before PHI's better division
+				// record would.
+				tm["PHI"].div = schedule.Record{Wins: 12, Losses: 4}
+				tm["NYK"].div = schedule.Record{Wins: 8, Losses: 8}
+			},
+			in:          []string{"BRK", "PHI", "NYK", "BOS"},
+			want:        []string{"BOS", "NYK", "PHI", "BRK"},
+			tiebreakers: []string{TiebreakHeadToHead, TiebreakHeadToHead, TiebreakHeadToHead, TiebreakHeadToHead},
+		},
+		{
+			name:      "nothing separates them",
+			divisions: map[string]string{"BOS": "Atlantic", "NYK": "Atlantic"},
+			setup: func(tm map[string]*team) {
+				series(tm, "BOS", "NYK", 2, 2)
+			},
+			in:          []string{"BOS", "NYK"},
+			want:        []string{"BOS", "NYK"},
+			tiebreakers: []string{"", ""},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			teams := tie(tt.divisions)
+			tt.setup(teams)
+			check(t, breakTie(order(teams, tt.in...)), tt.want, tt.tiebreakers)
+		})
+	}
+}
+
+func TestPointDiffLast(t *testing.T) {
+	teams := tie(map[string]string{"BOS": "Atlantic", "MIL": "Central", "MIA": "Southeast"})
+	teams["BOS"].pointsDiff, teams["MIL"].pointsDiff, teams["MIA"].pointsDiff = 10, 30, 20
+	teams["BOS"].conf = schedule.Record{Wins: 30, Losses: 22}
+	teams["MIL"].conf = schedule.Record{Wins: 26, Losses: 26}
+	teams["MIA"].conf = schedule.Record{Wins: 26, Losses: 26}
+	check(t, breakTie(order(teams, "MIA", "MIL", "BOS")),
+		[]string{"BOS", "MIL", "MIA"},
+		[]string{TiebreakConfRecord, TiebreakPointDiff, TiebreakPointDiff})
+}
@@ -0,0 +1,240 @@
+package stats
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	seasonpkg "github.com/umanchanda/NBA-API/season"
+)
+
+// Line is a set of summed counting stats with percentages recomputed from
+// the summed makes and attempts.
+type Line struct {
+	Seasons int      `json:"seasons,omitempty"`
+	G       int      `json:"g"`
+	GS      int      `json:"gs"`
+	MP      int      `json:"mp"`
+	FG      int      `json:"fg"`
+	FGA     int      `json:"fga"`
+	FGPct   *float64 `json:"fg_pct"`
+	FG3     int      `json:"fg3"`
+	FG3A    int      `json:"fg3a"`
+	FG3Pct  *float64 `json:"fg3_pct"`
+	FT      int      `json:"ft"`
+	FTA     int      `json:"fta"`
+	FTPct   *float64 `json:"ft_pct"`
+	ORB     int      `json:"orb"`
+	DRB     int      `json:"drb"`
+	TRB     int      `json:"trb"`
+	AST     int      `json:"ast"`
+	STL     int      `json:"stl"`
+	BLK     int      `json:"blk"`
+	TOV     int      `json:"tov"`
+	PF      int      `json:"pf"`
+	PTS     int      `json:"pts"`
+
+	seasons map[string]bool
+}
+
+// counting returns pointers to the counting stats of l keyed by column name.
+func (l *Line) counting() map[string]*int {
+	return map[string]*int{
+		"g": &l.G, "gs": &l.GS, "mp": &l.MP,
+		"fg": &l.FG, "fga": &l.FGA, "fg3": &l.FG3, "fg3a": &l.FG3A, "ft": &l.FT, "fta": &l.FTA,
+		"orb": &l.ORB, "drb": &l.DRB, "trb": &l.TRB,
+		"ast": &l.AST, "stl": &l.STL, "blk": &l.BLK, "tov": &l.TOV, "pf": &l.PF, "pts": &l.PTS,
+	}
+}
+
+// add sums a season row into l.
+func (l *Line) add(r Row) {
+	for col, v := range l.counting() {
+		*v += atoi(r.Get(col))
+	}
+	if l.seasons == nil {
+		l.seasons = make(map[string]bool)
+	}
+	l.seasons[r.Get("season")] = true
+}
+
+// finish computes the percentages and season count once every row is added.
+func (l *Line) finish() {
+	l.FGPct = pct(l.FG, l.FGA)
+	l.FG3Pct = pct(l.FG3, l.FG3A)
+	l.FTPct = pct(l.FT, l.FTA)
+	l.Seasons = len(l.seasons)
+}
+
+func pct(makes, attempts int) *float64 {
+	if attempts == 0 {
+		return nil
+	}
+	v := round(float64(makes)/float64(attempts), 3)
+	return &v
+}
+
+// atoi parses a stored stat, treating blanks as zero.
+func atoi(s string) int {
+	n, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil {
+		f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+		if err != nil {
+			return 0
+		}
+		return int(f)
+	}
+	return n
+}
+
+// TeamLine is a player's totals for one team.
+type TeamLine struct {
+	Team string `json:"team"`
+	Line
+}
+
+// SeasonLine is a player's totals for one season across every team.
+type SeasonLine struct {
+	Season      string   `json:"season"`
+	SeasonLabel string   `json:"season_label"`
+	Teams       []string `json:"teams"`
+	Line
+}
+
+// High is a player's best season for one stat.
+type High struct {
+	Season      string `json:"season"`
+	SeasonLabel string `json:"season_label"`
+	Value       int    `json:"value"`
+}
+
+// CareerSplit is a player's career for one season type.
+type CareerSplit struct {
+	Totals   Line            `json:"totals"`
+	ByTeam   []TeamLine      `json:"by_team"`
+	BySeason []SeasonLine    `json:"by_season"`
+	Highs    map[string]High `json:"highs"`
+}
+
+// Career is the /api/player/{id}/career response.
+type Career struct {
+	PlayerID string       `json:"player_id"`
+	Name     string       `json:"name"`
+	Regular  *CareerSplit `json:"regular,omitempty"`
+	Playoffs *CareerSplit `json:"playoffs,omitempty"`
+}
+
+// ErrNotFound is returned when a player id has no rows.
+var ErrNotFound = fmt.Errorf("player not found")
+
+// PlayerRows returns every row for a player, oldest first, leaving out the
+// multi-team rows basketball-reference adds for traded players.
+func PlayerRows(db *sql.DB, playerID string) ([]Row, error) {
+	rows, err := db.Query(`SELECT `+selectList(Columns)+`
+		FROM playerstats
+		WHERE player_id = $1 AND NOT `+aggregateTeam+`
+		ORDER BY season ASC, season_type DESC, id ASC`, playerID)
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	return scanRows(rows, Columns)
+}
+
+// PlayerCareer sums a player's seasons into career totals, broken down by
+// team and by season, with the best season for every counting stat.
+func PlayerCareer(db *sql.DB, playerID string) (Career, error) {
+	rows, err := PlayerRows(db, playerID)
+	if err != nil {
+		return Career{}, err
+	}
+	if len(rows) == 0 {
+		return Career{}, ErrNotFound
+	}
+
+	career := Career{PlayerID: playerID, Name: rows[len(rows)-1].Get("name")}
+	for _, seasonType := range []string{SeasonTypeRegular, SeasonTypePlayoffs} {
+		var typed []Row
+		for _, r := range rows {
+			if r.Get("season_type") == seasonType {
+				typed = append(typed, r)
+			}
+		}
+		if len(typed) == 0 {
+			continue
+		}
+		split := careerSplit(typed)
+		if seasonType == SeasonTypeRegular {
+			career.Regular = split
+		} else {
+			career.Playoffs = split
+		}
+	}
+	return career, nil
+}
+
+// careerSplit aggregates rows of a single season type, oldest first.
+func careerSplit(rows []Row) *CareerSplit {
+	split := &CareerSplit{Highs: make(map[string]High)}
+	teamIdx := make(map[string]int)
+	seasonIdx := make(map[string]int)
+
+	for _, r := range rows {
+		split.Totals.add(r)
+
+		team := r.Get("team")
+		i, ok := teamIdx[team]
+		if !ok {
+			i = len(split.ByTeam)
+			teamIdx[team] = i
+			split.ByTeam = append(split.ByTeam, TeamLine{Team: team})
+		}
+		split.ByTeam[i].add(r)
+
+		season := r.Get("season")
+		j, ok := seasonIdx[season]
+		if !ok {
+			j = len(split.BySeason)
+			seasonIdx[season] = j
+			split.BySeason = append(split.BySeason, SeasonLine{Season: season, SeasonLabel: seasonpkg.Label(season)})
+		}
+		split.BySeason[j].Teams = append(split.BySeason[j].Teams, team)
+		split.BySeason[j].add(r)
+	}
+
+	split.Totals.finish()
+	for i := range split.ByTeam {
+		split.ByTeam[i].finish()
+	}
+	sort.SliceStable(split.ByTeam, func(i, j int) bool {
+		return split.ByTeam[i].G > split.ByTeam[j].G
+	})
+	for i := range split.BySeason {
+		s := &split.BySeason[i]
+		s.finish()
+		s.Seasons = 0
+		for stat, v := range s.counting() {
+			if h, ok := split.Highs[stat]; !ok || *v > h.Value {
+				split.Highs[stat] = High{Season: s.Season, SeasonLabel: s.SeasonLabel, Value: *v}
+			}
+		}
+	}
+	return split
+}
+
+// SeasonRows returns one row per player-season of seasonType: the combined
+// line for traded players, otherwise their only row.
+func SeasonRows(db *sql.DB, seasonType string) ([]Row, error) {
+	filter := "season_type = $1"
+	rows, err := db.Query("WITH "+fmt.Sprintf(seasonRowsCTE, filter, filter)+`
+		SELECT `+selectList(Columns)+` FROM season_rows`, seasonType)
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	return scanRows(rows, Columns)
+}
@@ -0,0 +1,125 @@
+package stats
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Columns lists the playerstats columns the API exposes, in response order.
+var Columns = []string{
+	"player_id", "season", "season_type", "name", "team", "pos", "age",
+	"g", "gs", "mp",
+	"fg", "fga", "fg_pct", "fg3", "fg3a", "fg3_pct",
+	"ft", "fta", "ft_pct", "orb", "drb", "trb",
+	"ast", "stl", "blk", "tov", "pf", "pts",
+}
+
+// textColumns are compared as strings; every other column is numeric.
+var textColumns = map[string]bool{
+	"player_id":   true,
+	"season_type": true,
+	"name":        true,
+	"team":        true,
+	"pos":         true,
+}
+
+var columnSet = func() map[string]bool {
+	m := make(map[string]bool, len(Columns))
+	for _, c := range Columns {
+		m[c] = true
+	}
+	return m
+}()
+
+// IsColumn reports whether name is a whitelisted playerstats column.
+func IsColumn(name string) bool {
+	return columnSet[name]
+}
+
+// IsNumeric reports whether the column holds a number. Every column is
+// stored as TEXT, so numeric ones have to be cast before comparing.
+func IsNumeric(name string) bool {
+	return columnSet[name] && !textColumns[name]
+}
+
+// Expr returns the SQL expression for a whitelisted column, casting numeric
+// columns so they sort and compare as numbers. Empty strings become NULL.
+func Expr(name string) string {
+	if IsNumeric(name) {
+		return "NULLIF(" + name + ", '')::numeric"
+	}
+	return "COALESCE(" + name + ", '')"
+}
+
+// ResolveColumn is a filter.Resolver over the whitelisted columns.
+func ResolveColumn(name string) (string, bool, bool) {
+	if !IsColumn(name) {
+		return "", false, false
+	}
+	return Expr(name), IsNumeric(name), true
+}
+
+// SortKey is one column of a sort= parameter.
+type SortKey struct {
+	Column string
+	Desc   bool
+}
+
+// ParseSort parses "pts:desc,season:asc". The direction defaults to asc.
+func ParseSort(s string) ([]SortKey, error) {
+	var keys []SortKey
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		col, dir, _ := strings.Cut(part, ":")
+		if !IsColumn(col) {
+			return nil, fmt.Errorf("cannot sort by %q", col)
+		}
+		switch strings.ToLower(dir) {
+		case "", "asc":
+			keys = append(keys, SortKey{Column: col})
+		case "desc":
+			keys = append(keys, SortKey{Column: col, Desc: true})
+		default:
+			return nil, fmt.Errorf("sort direction for %s must be asc or desc", col)
+		}
+	}
+	return keys, nil
+}
+
+// ParseFields parses a comma-separated fields= list. An empty list means
+// every column.
+func ParseFields(s string) ([]string, error) {
+	var fields []string
+	for _, f := range strings.Split(s, ",") {
+		f = strings.TrimSpace(f)
+		if f == "" {
+			continue
+		}
+		if !IsColumn(f) {
+			return nil, fmt.Errorf("unknown field %q", f)
+		}
+		fields = append(fields, f)
+	}
+	if len(fields) == 0 {
+		return Columns, nil
+	}
+	return fields, nil
+}
+
+// orderBy renders keys as an ORDER BY clause, numeric columns last when NULL
+// and the row id as a final tiebreaker so pages never overlap.
+func orderBy(keys []SortKey) string {
+	parts := make([]string, 0, len(keys)+1)
+	for _, k := range keys {
+		dir := "ASC"
+		if k.Desc {
+			dir = "DESC"
+		}
+		parts = append(parts, Expr(k.Column)+" "+dir+" NULLS LAST")
+	}
+	parts = append(parts, "id ASC")
+	return " ORDER BY " + strings.Join(parts, ", ")
+}
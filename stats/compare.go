@@ -0,0 +1,210 @@
+package stats
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/umanchanda/NBA-API/season"
+)
+
+const (
+	ModeTotals  = "totals"
+	ModePerGame = "per_game"
+	ModePer36   = "per36"
+	ModeCareer  = "career"
+)
+
+// compareStats are the rows of a comparison, in order.
+var compareStats = []string{
+	"g", "gs", "mp",
+	"fg", "fga", "fg_pct", "fg3", "fg3a", "fg3_pct", "ft", "fta", "ft_pct",
+	"orb", "drb", "trb", "ast", "stl", "blk", "tov", "pf", "pts",
+}
+
+// value returns stat from l in the given mode, or nil when it does not
+// apply (games per game) or cannot be computed (no attempts).
+func (l *Line) value(stat, mode string) *float64 {
+	switch stat {
+	case "fg_pct":
+		return l.FGPct
+	case "fg3_pct":
+		return l.FG3Pct
+	case "ft_pct":
+		return l.FTPct
+	}
+	n, ok := l.counting()[stat]
+	if !ok {
+		return nil
+	}
+
+	var v float64
+	switch mode {
+	case ModeTotals, ModeCareer:
+		v = float64(*n)
+	case ModePerGame:
+		if stat == "g" || stat == "gs" || l.G == 0 {
+			return nil
+		}
+		v = float64(*n) / float64(l.G)
+	case ModePer36:
+		if stat == "g" || stat == "gs" || stat == "mp" || l.MP == 0 {
+			return nil
+		}
+		v = float64(*n) * 36 / float64(l.MP)
+	}
+	v = round(v, 1)
+	return &v
+}
+
+// CompareQuery describes a /api/compare request.
+type CompareQuery struct {
+	PlayerIDs  []string
+	Season     string
+	SeasonType string
+	Mode       string
+}
+
+// ComparedPlayer is one column of a comparison.
+type ComparedPlayer struct {
+	PlayerID string   `json:"player_id"`
+	Name     string   `json:"name"`
+	Teams    []string `json:"teams"`
+}
+
+// CompareRow is one stat across every compared player, aligned with
+// Comparison.Players. VsLeague holds each value minus the league average.
+type CompareRow struct {
+	Stat     string     `json:"stat"`
+	Values   []*float64 `json:"values"`
+	League   *float64   `json:"league,omitempty"`
+	VsLeague []*float64 `json:"vs_league,omitempty"`
+}
+
+// Comparison is the /api/compare response.
+type Comparison struct {
+	Season      string           `json:"season,omitempty"`
+	SeasonLabel string           `json:"season_label,omitempty"`
+	SeasonType  string           `json:"season_type"`
+	Mode        string           `json:"mode"`
+	Players     []ComparedPlayer `json:"players"`
+	Rows        []CompareRow     `json:"rows"`
+}
+
+// leagueLine sums every player's line for a season. The second result is
+// the number of distinct players, the divisor for a league-average total.
+func leagueLine(db *sql.DB, season, seasonType string) (Line, int, error) {
+	var l Line
+	cols := []string{"COUNT(DISTINCT COALESCE(player_id, name))"}
+	dest := []interface{}{new(int)}
+	for _, stat := range compareStats {
+		if n, ok := l.counting()[stat]; ok {
+			cols = append(cols, "COALESCE(SUM("+Expr(stat)+"), 0)::bigint")
+			dest = append(dest, n)
+		}
+	}
+	err := db.QueryRow(`SELECT `+strings.Join(cols, ", ")+`
+		FROM playerstats
+		WHERE season = $1 AND season_type = $2 AND NOT `+aggregateTeam,
+		season, seasonType).Scan(dest...)
+	if err != nil {
+		return Line{}, 0, fmt.Errorf("league totals: %w", err)
+	}
+	l.finish()
+	return l, *dest[0].(*int), nil
+}
+
+// Compare lines several players up stat by stat. In every mode but career
+// each value is also compared with the league average for the season: the
+// average player's total, or the league-wide rate per game or per 36.
+func Compare(db *sql.DB, q CompareQuery) (Comparison, error) {
+	if q.SeasonType == "" {
+		q.SeasonType = SeasonTypeRegular
+	}
+	if q.Mode == "" {
+		q.Mode = ModePerGame
+	}
+	switch q.Mode {
+	case ModeTotals, ModePerGame, ModePer36:
+		if q.Season == "" {
+			return Comparison{}, ErrBadQuery{fmt.Errorf("season is required unless mode=%s", ModeCareer)}
+		}
+	case ModeCareer:
+		q.Season = ""
+	default:
+		return Comparison{}, ErrBadQuery{fmt.Errorf("mode must be %s, %s, %s or %s", ModeTotals, ModePerGame, ModePer36, ModeCareer)}
+	}
+	if len(q.PlayerIDs) < 2 {
+		return Comparison{}, ErrBadQuery{fmt.Errorf("players needs at least two ids")}
+	}
+
+	cmp := Comparison{Season: q.Season, SeasonType: q.SeasonType, Mode: q.Mode}
+	if q.Season != "" {
+		cmp.SeasonLabel = season.Label(q.Season)
+	}
+	lines := make([]Line, len(q.PlayerIDs))
+	for i, id := range q.PlayerIDs {
+		rows, err := PlayerRows(db, id)
+		if err != nil {
+			return Comparison{}, err
+		}
+		if len(rows) == 0 {
+			return Comparison{}, fmt.Errorf("%w: %s", ErrNotFound, id)
+		}
+		p := ComparedPlayer{PlayerID: id, Teams: []string{}}
+		for _, r := range rows {
+			if r.Get("season_type") != q.SeasonType || (q.Season != "" && r.Get("season") != q.Season) {
+				continue
+			}
+			p.Name = r.Get("name")
+			p.Teams = append(p.Teams, r.Get("team"))
+			lines[i].add(r)
+		}
+		if p.Name == "" {
+			return Comparison{}, ErrBadQuery{fmt.Errorf("no %s stats for %s", q.SeasonType, id)}
+		}
+		lines[i].finish()
+		cmp.Players = append(cmp.Players, p)
+	}
+
+	var league Line
+	var players int
+	if q.Mode != ModeCareer {
+		var err error
+		if league, players, err = leagueLine(db, q.Season, q.SeasonType); err != nil {
+			return Comparison{}, err
+		}
+	}
+
+	for _, stat := range compareStats {
+		row := CompareRow{Stat: stat, Values: make([]*float64, len(lines))}
+		present := false
+		for i := range lines {
+			row.Values[i] = lines[i].value(stat, q.Mode)
+			present = present || row.Values[i] != nil
+		}
+		if !present {
+			continue
+		}
+
+		if players > 0 {
+			avg := league.value(stat, q.Mode)
+			if avg != nil && q.Mode == ModeTotals && !strings.HasSuffix(stat, "_pct") {
+				v := round(*avg/float64(players), 1)
+				avg = &v
+			}
+			if avg != nil {
+				row.League = avg
+				row.VsLeague = make([]*float64, len(lines))
+				for i, v := range row.Values {
+					if v != nil {
+						d := round(*v-*avg, 3)
+						row.VsLeague[i] = &d
+					}
+				}
+			}
+		}
+		cmp.Rows = append(cmp.Rows, row)
+	}
+	return cmp, nil
+}
@@ -0,0 +1,186 @@
+package stats
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/lib/pq"
+)
+
+// contextStats are the stats /api/player?context=true measures against the
+// season: counting stats per game, and the shooting percentages.
+var contextStats = []string{
+	"mp", "fg", "fga", "fg3", "fg3a", "ft", "fta",
+	"orb", "drb", "trb", "ast", "stl", "blk", "tov", "pf", "pts",
+	"fg_pct", "fg3_pct", "ft_pct",
+}
+
+// StatContext places one stat of a player-season among the qualified
+// player-seasons of the same season. Plus is the value as a percentage of
+// their mean, so 100 is league average.
+type StatContext struct {
+	Value      float64  `json:"value"`
+	Percentile float64  `json:"percentile"`
+	ZScore     *float64 `json:"z_score,omitempty"`
+	Plus       *float64 `json:"plus,omitempty"`
+}
+
+// distribution is the sorted values of one stat over a season's pool.
+type distribution struct {
+	values   []float64
+	mean, sd float64
+}
+
+func newDistribution(values []float64) *distribution {
+	sort.Float64s(values)
+	d := &distribution{values: values}
+	for _, v := range values {
+		d.mean += v
+	}
+	d.mean /= float64(len(values))
+	for _, v := range values {
+		d.sd += (v - d.mean) * (v - d.mean)
+	}
+	d.sd = math.Sqrt(d.sd / float64(len(values)))
+	return d
+}
+
+// context measures v against d. Percentile is the share of the pool at or
+// below v, as cume_dist computes it for the leaderboard.
+func (d *distribution) context(v float64) *StatContext {
+	above := sort.Search(len(d.values), func(i int) bool { return d.values[i] > v })
+	c := &StatContext{
+		Value:      round(v, 3),
+		Percentile: round(100*float64(above)/float64(len(d.values)), 1),
+	}
+	if d.sd > 0 {
+		c.ZScore = roundPtr((v-d.mean)/d.sd, 2)
+	}
+	if d.mean != 0 {
+		c.Plus = roundPtr(100*v/d.mean, 0)
+	}
+	return c
+}
+
+// contextValue returns stat for r as it is compared: per game for counting
+// stats, and recomputed from makes and attempts for percentages when the
+// row has them.
+func contextValue(r Row, stat string) (float64, bool) {
+	num := func(f string) (float64, bool) {
+		v, err := strconv.ParseFloat(r.Get(f), 64)
+		return v, err == nil
+	}
+	if rs, ok := rateStats[stat]; ok {
+		makes, okM := num(rs.makes)
+		attempts, okA := num(rs.attempts)
+		if okM && okA {
+			if attempts == 0 {
+				return 0, false
+			}
+			return makes / attempts, true
+		}
+		return num(stat)
+	}
+	v, okV := num(stat)
+	g, okG := num("g")
+	if !okV || !okG || g == 0 {
+		return 0, false
+	}
+	return v / g, true
+}
+
+// qualifies reports whether a season row belongs in the pool for stat,
+// using the leaderboard's per-game and percentage minimums.
+func qualifies(r Row, stat string) bool {
+	minG, minMakes := minimums(stat, PerGame, r.Get("season"), r.Get("season_type"))
+	if g, _ := strconv.Atoi(r.Get("g")); g < minG {
+		return false
+	}
+	if rs, ok := rateStats[stat]; ok {
+		if makes, _ := strconv.Atoi(r.Get(rs.makes)); makes < minMakes {
+			return false
+		}
+	}
+	return true
+}
+
+// AddContext fills in Context on every row with the percentile, z-score and
+// plus index of each stat it has, within its season and season type. Rows
+// without a season or season type are left alone.
+func AddContext(db *sql.DB, rows []Row) error {
+	type key struct{ season, seasonType string }
+	need := make(map[key]bool)
+	var seasons, types []string
+	for _, r := range rows {
+		k := key{r.Get("season"), r.Get("season_type")}
+		if k.season == "" || k.seasonType == "" || need[k] {
+			continue
+		}
+		need[k] = true
+		seasons = append(seasons, k.season)
+		types = append(types, k.seasonType)
+	}
+	if len(need) == 0 {
+		return nil
+	}
+
+	fields := append([]string{"season", "season_type", "g"}, contextStats...)
+	filter := "season = ANY($1) AND season_type = ANY($2)"
+	res, err := db.Query("WITH "+fmt.Sprintf(seasonRowsCTE, filter, filter)+`
+		SELECT `+selectList(fields)+` FROM season_rows`, pq.Array(seasons), pq.Array(types))
+	if err != nil {
+		return fmt.Errorf("query failed: %w", err)
+	}
+	defer res.Close()
+	pool, err := scanRows(res, fields)
+	if err != nil {
+		return err
+	}
+
+	values := make(map[key]map[string][]float64)
+	for _, r := range pool {
+		k := key{r.Get("season"), r.Get("season_type")}
+		if !need[k] {
+			continue
+		}
+		if values[k] == nil {
+			values[k] = make(map[string][]float64)
+		}
+		for _, stat := range contextStats {
+			if v, ok := contextValue(r, stat); ok && qualifies(r, stat) {
+				values[k][stat] = append(values[k][stat], v)
+			}
+		}
+	}
+	dists := make(map[key]map[string]*distribution)
+	for k, byStat := range values {
+		dists[k] = make(map[string]*distribution)
+		for stat, vs := range byStat {
+			dists[k][stat] = newDistribution(vs)
+		}
+	}
+
+	for i := range rows {
+		byStat := dists[key{rows[i].Get("season"), rows[i].Get("season_type")}]
+		if byStat == nil {
+			continue
+		}
+		rows[i].Context = make(map[string]*StatContext)
+		for _, stat := range contextStats {
+			if !rows[i].has(stat) {
+				continue
+			}
+			d, ok := byStat[stat]
+			if !ok {
+				continue
+			}
+			if v, ok := contextValue(rows[i], stat); ok {
+				rows[i].Context[stat] = d.context(v)
+			}
+		}
+	}
+	return nil
+}
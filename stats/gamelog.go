@@ -0,0 +1,165 @@
+package stats
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/umanchanda/NBA-API/season"
+)
+
+// ErrNoGameLogs is returned before the seed command has created and filled
+// the player_games table.
+var ErrNoGameLogs = fmt.Errorf("no game logs are stored yet; run the seed command with -gamelogs")
+
+// gameLogErr turns the error for a missing player_games table into
+// ErrNoGameLogs.
+func gameLogErr(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "42P01" { // undefined_table
+		return ErrNoGameLogs
+	}
+	return fmt.Errorf("query failed: %w", err)
+}
+
+// Game is one line of a player's game log.
+type Game struct {
+	GameID     string   `json:"game_id"`
+	Date       string   `json:"date"`
+	SeasonType string   `json:"season_type"`
+	Team       string   `json:"team"`
+	Opp        string   `json:"opp"`
+	HomeAway   string   `json:"home_away"`
+	Result     string   `json:"result"`
+	Margin     int      `json:"margin"`
+	TeamPts    *int     `json:"team_pts,omitempty"`
+	OppPts     *int     `json:"opp_pts,omitempty"`
+	Started    bool     `json:"started"`
+	MP         string   `json:"mp"`
+	FG         int      `json:"fg"`
+	FGA        int      `json:"fga"`
+	FGPct      *float64 `json:"fg_pct"`
+	FG3        int      `json:"fg3"`
+	FG3A       int      `json:"fg3a"`
+	FG3Pct     *float64 `json:"fg3_pct"`
+	FT         int      `json:"ft"`
+	FTA        int      `json:"fta"`
+	FTPct      *float64 `json:"ft_pct"`
+	ORB        int      `json:"orb"`
+	DRB        int      `json:"drb"`
+	TRB        int      `json:"trb"`
+	AST        int      `json:"ast"`
+	STL        int      `json:"stl"`
+	BLK        int      `json:"blk"`
+	TOV        int      `json:"tov"`
+	PF         int      `json:"pf"`
+	PTS        int      `json:"pts"`
+	GameScore  *float64 `json:"game_score,omitempty"`
+	PlusMinus  *int     `json:"plus_minus,omitempty"`
+}
+
+// GameLog is the /api/player/{id}/gamelog response.
+type GameLog struct {
+	PlayerID    string `json:"player_id"`
+	Name        string `json:"name"`
+	Season      string `json:"season"`
+	SeasonLabel string `json:"season_label"`
+	SeasonType  string `json:"season_type,omitempty"`
+	Games       []Game `json:"games"`
+}
+
+// PlayerGameLog returns a player's games in a season, oldest first, from
+// the player_games table the seed command backfills. An empty season means
+// the latest one stored, and an empty seasonType returns the regular season
+// and playoffs together.
+func PlayerGameLog(db *sql.DB, playerID, seasonKey, seasonType string) (GameLog, error) {
+	if seasonType != "" && seasonType != SeasonTypeRegular && seasonType != SeasonTypePlayoffs {
+		return GameLog{}, ErrBadQuery{fmt.Errorf("season_type must be %s or %s", SeasonTypeRegular, SeasonTypePlayoffs)}
+	}
+	if seasonKey == "" {
+		var latest sql.NullString
+		err := db.QueryRow(`SELECT MAX(season) FROM player_games WHERE player_id = $1`, playerID).Scan(&latest)
+		if err != nil {
+			return GameLog{}, gameLogErr(err)
+		}
+		if !latest.Valid {
+			return GameLog{}, ErrNotFound
+		}
+		seasonKey = latest.String
+	}
+
+	gl := GameLog{
+		PlayerID:    playerID,
+		Season:      seasonKey,
+		SeasonLabel: season.Label(seasonKey),
+		SeasonType:  seasonType,
+		Games:       []Game{},
+	}
+	err := db.QueryRow(`SELECT name FROM playerstats WHERE player_id = $1 ORDER BY season DESC LIMIT 1`,
+		playerID).Scan(&gl.Name)
+	if err == sql.ErrNoRows {
+		return GameLog{}, ErrNotFound
+	}
+	if err != nil {
+		return GameLog{}, fmt.Errorf("query failed: %w", err)
+	}
+
+	rows, err := db.Query(`SELECT game_id, date, season_type, team, opp, home,
+			COALESCE(result, ''), COALESCE(margin, 0), team_pts, opp_pts, started, seconds,
+			fg, fga, fg3, fg3a, ft, fta, orb, drb, trb, ast, stl, blk, tov, pf, pts,
+			game_score, plus_minus
+		FROM player_games
+		WHERE player_id = $1 AND season = $2 AND ($3 = '' OR season_type = $3)
+		ORDER BY date ASC`, playerID, seasonKey, seasonType)
+	if err != nil {
+		return GameLog{}, gameLogErr(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var g Game
+		var date time.Time
+		var home bool
+		var secs int
+		var teamPts, oppPts, plusMinus sql.NullInt64
+		var gameScore sql.NullFloat64
+		err := rows.Scan(&g.GameID, &date, &g.SeasonType, &g.Team, &g.Opp, &home,
+			&g.Result, &g.Margin, &teamPts, &oppPts, &g.Started, &secs,
+			&g.FG, &g.FGA, &g.FG3, &g.FG3A, &g.FT, &g.FTA, &g.ORB, &g.DRB, &g.TRB,
+			&g.AST, &g.STL, &g.BLK, &g.TOV, &g.PF, &g.PTS, &gameScore, &plusMinus)
+		if err != nil {
+			return GameLog{}, fmt.Errorf("scan failed: %w", err)
+		}
+		g.Date = date.Format("2006-01-02")
+		g.HomeAway = "away"
+		if home {
+			g.HomeAway = "home"
+		}
+		g.MP = fmt.Sprintf("%d:%02d", secs/60, secs%60)
+		g.TeamPts = nullInt(teamPts)
+		g.OppPts = nullInt(oppPts)
+		g.PlusMinus = nullInt(plusMinus)
+		if gameScore.Valid {
+			g.GameScore = roundPtr(gameScore.Float64, 1)
+		}
+		g.FGPct = pct(g.FG, g.FGA)
+		g.FG3Pct = pct(g.FG3, g.FG3A)
+		g.FTPct = pct(g.FT, g.FTA)
+		gl.Games = append(gl.Games, g)
+	}
+	if err := rows.Err(); err != nil {
+		return GameLog{}, fmt.Errorf("query failed: %w", err)
+	}
+	return gl, nil
+}
+
+func nullInt(n sql.NullInt64) *int {
+	if !n.Valid {
+		return nil
+	}
+	v := int(n.Int64)
+	return &v
+}
@@ -0,0 +1,343 @@
+package stats
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/umanchanda/NBA-API/season"
+)
+
+const (
+	SeasonTypeRegular  = "regular"
+	SeasonTypePlayoffs = "playoffs"
+)
+
+const (
+	PerTotal = "total"
+	PerGame  = "game"
+	Per36    = "36"
+)
+
+// What a leaderboard can be ordered by: the stat itself, or its z-score or
+// plus index within the season.
+const (
+	RankByValue  = "value"
+	RankByZScore = "z_score"
+	RankByPlus   = "plus"
+)
+
+// countingStats can be ranked as totals, per game or per 36 minutes.
+var countingStats = map[string]bool{
+	"g": true, "gs": true, "mp": true,
+	"fg": true, "fga": true, "fg3": true, "fg3a": true, "ft": true, "fta": true,
+	"orb": true, "drb": true, "trb": true,
+	"ast": true, "stl": true, "blk": true, "tov": true, "pf": true, "pts": true,
+}
+
+// rateStats are recomputed from makes and attempts rather than read from
+// the stored percentage, and need a minimum number of makes to qualify.
+var rateStats = map[string]struct {
+	makes, attempts string
+	minMakes        int // over an 82-game season
+}{
+	"fg_pct":  {"fg", "fga", 300},
+	"fg3_pct": {"fg3", "fg3a", 82},
+	"ft_pct":  {"ft", "fta", 125},
+}
+
+// playoffMinMakes stands in for minMakes in the playoffs, where
+// basketball-reference has no fixed scale: roughly two series' worth.
+var playoffMinMakes = map[string]int{
+	"fg_pct":  40,
+	"fg3_pct": 12,
+	"ft_pct":  20,
+}
+
+// aggregateTeam matches the multi-team rows basketball-reference adds for
+// traded players: "TOT" on older pages, "2TM", "3TM", ... on newer ones.
+const aggregateTeam = `(team = 'TOT' OR team ~ '^[0-9]TM$')`
+
+// aggregateTeamOf is aggregateTeam for the table aliased as alias.
+func aggregateTeamOf(alias string) string {
+	return "(" + alias + ".team = 'TOT' OR " + alias + ".team ~ '^[0-9]TM$')"
+}
+
+// seasonRowsCTE keeps one row per player-season: the multi-team row for
+// traded players, otherwise their only row. teams lists every team the
+// player appeared for in that season, in the order they played for them.
+const seasonRowsCTE = `season_rows AS (
+	SELECT DISTINCT ON (COALESCE(player_id, name), season, season_type) *
+	FROM playerstats
+	WHERE %s
+	ORDER BY COALESCE(player_id, name), season, season_type, ` + aggregateTeam + ` DESC, id
+), season_teams AS (
+	SELECT COALESCE(player_id, name) AS pkey, season, season_type,
+		string_agg(team, ',' ORDER BY id) AS teams
+	FROM playerstats
+	WHERE %s AND NOT ` + aggregateTeam + `
+	GROUP BY 1, 2, 3
+)`
+
+// LeaderQuery describes a /api/leaders request.
+type LeaderQuery struct {
+	Stat       string
+	Season     string // empty ranks every season together
+	SeasonType string
+	Per        string
+	MinG       int // -1 applies the default qualifier
+	Limit      int
+	Context    bool // add each leader's percentile, z-score and plus index
+	RankBy     string
+}
+
+// Leader is one ranked player-season.
+type Leader struct {
+	Rank        int      `json:"rank"`
+	PlayerID    string   `json:"player_id"`
+	Name        string   `json:"name"`
+	Season      string   `json:"season"`
+	SeasonLabel string   `json:"season_label"`
+	SeasonType  string   `json:"season_type"`
+	Team        string   `json:"team"`
+	Teams       []string `json:"teams,omitempty"`
+	G           int      `json:"g"`
+	Value       float64  `json:"value"`
+	Percentile  *float64 `json:"percentile,omitempty"`
+	ZScore      *float64 `json:"z_score,omitempty"`
+	Plus        *float64 `json:"plus,omitempty"`
+}
+
+// Leaderboard is the /api/leaders response.
+type Leaderboard struct {
+	Stat        string   `json:"stat"`
+	Per         string   `json:"per"`
+	Season      string   `json:"season,omitempty"`
+	SeasonLabel string   `json:"season_label,omitempty"`
+	SeasonType  string   `json:"season_type"`
+	Qualifier   string   `json:"qualifier,omitempty"`
+	RankBy      string   `json:"rank_by"`
+	Leaders     []Leader `json:"leaders"`
+}
+
+// valueExpr returns the SQL for the ranked value of stat.
+func valueExpr(stat, per string) string {
+	if r, ok := rateStats[stat]; ok {
+		return fmt.Sprintf("%s / NULLIF(%s, 0)", Expr(r.makes), Expr(r.attempts))
+	}
+	switch per {
+	case PerGame:
+		return fmt.Sprintf("%s / NULLIF(%s, 0)", Expr(stat), Expr("g"))
+	case Per36:
+		return fmt.Sprintf("%s * 36 / NULLIF(%s, 0)", Expr(stat), Expr("mp"))
+	}
+	return Expr(stat)
+}
+
+// minimums returns the games and makes a player-season needs to qualify
+// for stat. Following basketball-reference, per-game and per-36 rankings
+// need 70% of the team's games and percentages a minimum number of makes,
+// both scaled to the season length. A season of "" uses the 82-game
+// thresholds.
+func minimums(stat, per, seasonKey, seasonType string) (minG, minMakes int) {
+	games := 82
+	if s, err := season.Parse(seasonKey); err == nil {
+		games = s.Games()
+	}
+	playoffs := seasonType == SeasonTypePlayoffs
+	if r, ok := rateStats[stat]; ok {
+		if playoffs {
+			return 0, playoffMinMakes[stat]
+		}
+		return 0, int(math.Ceil(float64(r.minMakes) * float64(games) / 82))
+	}
+	if !playoffs && per != PerTotal {
+		minG = int(math.Ceil(0.7 * float64(games)))
+	}
+	return minG, 0
+}
+
+// qualifier returns the SQL condition a player-season must meet to be
+// ranked, and a description of it. An explicit MinG replaces the games
+// requirement from minimums.
+func qualifier(q LeaderQuery) (string, string) {
+	minG, makes := minimums(q.Stat, q.Per, q.Season, q.SeasonType)
+	if q.MinG >= 0 {
+		minG = q.MinG
+	}
+
+	var conds, desc []string
+	if minG > 0 {
+		conds = append(conds, fmt.Sprintf("%s >= %d", Expr("g"), minG))
+		desc = append(desc, fmt.Sprintf("%d games", minG))
+	}
+	if r, ok := rateStats[q.Stat]; ok {
+		conds = append(conds, fmt.Sprintf("%s >= %d", Expr(r.makes), makes))
+		desc = append(desc, fmt.Sprintf("%d %s", makes, r.makes))
+	}
+
+	if len(conds) == 0 {
+		return "TRUE", ""
+	}
+	return strings.Join(conds, " AND "), "minimum " + strings.Join(desc, ", ")
+}
+
+// validateLeaderQuery checks the stat and per values of q.
+func validateLeaderQuery(q LeaderQuery) error {
+	if !countingStats[q.Stat] {
+		if _, ok := rateStats[q.Stat]; !ok {
+			return fmt.Errorf("cannot rank by %q", q.Stat)
+		}
+	}
+	switch q.Per {
+	case PerTotal, PerGame, Per36:
+	default:
+		return fmt.Errorf("per must be %s, %s or %s", PerTotal, PerGame, Per36)
+	}
+	switch q.SeasonType {
+	case SeasonTypeRegular, SeasonTypePlayoffs:
+	default:
+		return fmt.Errorf("season_type must be %s or %s", SeasonTypeRegular, SeasonTypePlayoffs)
+	}
+	switch q.RankBy {
+	case RankByValue, RankByZScore, RankByPlus:
+	default:
+		return fmt.Errorf("rank_by must be %s, %s or %s", RankByValue, RankByZScore, RankByPlus)
+	}
+	return nil
+}
+
+// Leaders ranks player-seasons on a counting or rate stat. Traded players
+// are ranked once, on their combined line, with every team they played for.
+//
+// Every qualified player-season is also measured against the others from
+// the same season: percentile is the share at or below it, z_score its
+// distance from their mean in standard deviations, and plus its value as a
+// percentage of that mean, so 100 is average. Ranking every season by
+// z_score or plus compares players across eras.
+func Leaders(db *sql.DB, q LeaderQuery) (Leaderboard, error) {
+	if q.Per == "" {
+		q.Per = PerTotal
+	}
+	if q.SeasonType == "" {
+		q.SeasonType = SeasonTypeRegular
+	}
+	if q.Limit <= 0 {
+		q.Limit = 25
+	}
+	if q.RankBy == "" {
+		q.RankBy = RankByValue
+	}
+	if q.RankBy != RankByValue {
+		q.Context = true
+	}
+	if err := validateLeaderQuery(q); err != nil {
+		return Leaderboard{}, ErrBadQuery{err}
+	}
+	if _, ok := rateStats[q.Stat]; ok {
+		q.Per = PerTotal
+	}
+
+	filter := "season_type = $1"
+	args := []interface{}{q.SeasonType}
+	if q.Season != "" {
+		args = append(args, q.Season)
+		filter += " AND season = $2"
+	}
+	cond, desc := qualifier(q)
+	args = append(args, q.Limit)
+
+	value := valueExpr(q.Stat, q.Per)
+
+	query := "WITH " + fmt.Sprintf(seasonRowsCTE, filter, filter) + `, pool AS (
+			SELECT r.*, ` + value + ` AS value
+			FROM season_rows r
+			WHERE ` + cond + ` AND ` + value + ` IS NOT NULL
+		), scored AS (
+			SELECT p.*,
+				cume_dist() OVER (w ORDER BY value) AS percentile,
+				(value - avg(value) OVER w) / NULLIF(stddev_pop(value) OVER w, 0) AS z_score,
+				100 * value / NULLIF(avg(value) OVER w, 0) AS plus
+			FROM pool p
+			WINDOW w AS (PARTITION BY season)
+		)
+		SELECT COALESCE(s.player_id, ''), s.name, s.season, s.season_type, s.team,
+			COALESCE(t.teams, s.team), COALESCE(` + Expr("g") + `, 0), s.value, s.percentile, s.z_score, s.plus
+		FROM scored s
+		LEFT JOIN season_teams t
+			ON t.pkey = COALESCE(s.player_id, s.name) AND t.season = s.season AND t.season_type = s.season_type
+		ORDER BY s.` + q.RankBy + ` DESC NULLS LAST, s.name
+		LIMIT $` + strconv.Itoa(len(args))
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return Leaderboard{}, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	board := Leaderboard{
+		Stat:       q.Stat,
+		Per:        q.Per,
+		Season:     q.Season,
+		SeasonType: q.SeasonType,
+		Qualifier:  desc,
+		RankBy:     q.RankBy,
+		Leaders:    []Leader{},
+	}
+	if q.Season != "" {
+		board.SeasonLabel = season.Label(q.Season)
+	}
+	var prevKey float64
+	for rows.Next() {
+		var l Leader
+		var teams string
+		var percentile float64
+		var z, plus sql.NullFloat64
+		if err := rows.Scan(&l.PlayerID, &l.Name, &l.Season, &l.SeasonType, &l.Team, &teams, &l.G, &l.Value, &percentile, &z, &plus); err != nil {
+			return Leaderboard{}, fmt.Errorf("scan failed: %w", err)
+		}
+		key := l.Value
+		switch q.RankBy {
+		case RankByZScore:
+			key = z.Float64
+		case RankByPlus:
+			key = plus.Float64
+		}
+		key = round(key, 3)
+
+		l.Value = round(l.Value, 3)
+		l.SeasonLabel = season.Label(l.Season)
+		if strings.Contains(teams, ",") {
+			l.Teams = strings.Split(teams, ",")
+		}
+		if q.Context {
+			l.Percentile = roundPtr(100*percentile, 1)
+			if z.Valid {
+				l.ZScore = roundPtr(z.Float64, 2)
+			}
+			if plus.Valid {
+				l.Plus = roundPtr(plus.Float64, 0)
+			}
+		}
+
+		l.Rank = len(board.Leaders) + 1
+		if prev := len(board.Leaders) - 1; prev >= 0 && prevKey == key {
+			l.Rank = board.Leaders[prev].Rank
+		}
+		prevKey = key
+		board.Leaders = append(board.Leaders, l)
+	}
+	return board, rows.Err()
+}
+
+func round(v float64, places int) float64 {
+	p := math.Pow(10, float64(places))
+	return math.Round(v*p) / p
+}
+
+func roundPtr(v float64, places int) *float64 {
+	v = round(v, places)
+	return &v
+}
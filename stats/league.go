@@ -0,0 +1,139 @@
+package stats
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+
+	"github.com/umanchanda/NBA-API/season"
+)
+
+// ageBands split minutes played by age, inclusive on both ends.
+var ageBands = []struct {
+	label  string
+	lo, hi int
+}{
+	{"22_and_under", 0, 22},
+	{"23_to_25", 23, 25},
+	{"26_to_29", 26, 29},
+	{"30_to_33", 30, 33},
+	{"34_and_over", 34, 99},
+}
+
+// LeagueSeason is one point of the /api/league/averages time series. Per
+// team game figures divide league totals by the number of team games.
+type LeagueSeason struct {
+	Season       string             `json:"season"`
+	SeasonLabel  string             `json:"season_label"`
+	Teams        int                `json:"teams"`
+	TeamGames    int                `json:"team_games"`
+	PTS          float64            `json:"pts_per_game"`
+	FGA          float64            `json:"fga_per_game"`
+	FG3A         float64            `json:"fg3a_per_game"`
+	FTA          float64            `json:"fta_per_game"`
+	TRB          float64            `json:"trb_per_game"`
+	AST          float64            `json:"ast_per_game"`
+	TOV          float64            `json:"tov_per_game"`
+	FGPct        *float64           `json:"fg_pct"`
+	FG3Pct       *float64           `json:"fg3_pct"`
+	FTPct        *float64           `json:"ft_pct"`
+	FG3ARate     *float64           `json:"fg3a_rate"`
+	FTRate       *float64           `json:"ft_rate"`
+	MinutesByAge map[string]float64 `json:"minutes_share_by_age"`
+}
+
+// LeagueAverages is the /api/league/averages response.
+type LeagueAverages struct {
+	From       string         `json:"from"`
+	To         string         `json:"to"`
+	SeasonType string         `json:"season_type"`
+	Seasons    []LeagueSeason `json:"seasons"`
+}
+
+// LeagueAveragesBetween computes league-wide totals and rates for every
+// season from through to, by end year, oldest first. 3PA rate and FT rate
+// are per field goal attempt. Regular-season team games are the number of
+// teams times the schedule length; playoff team games are estimated from
+// minutes played, 240 to a game. An empty from starts at the first season
+// stored.
+func LeagueAveragesBetween(db *sql.DB, from, to, seasonType string) (LeagueAverages, error) {
+	if seasonType == "" {
+		seasonType = SeasonTypeRegular
+	}
+	if seasonType != SeasonTypeRegular && seasonType != SeasonTypePlayoffs {
+		return LeagueAverages{}, ErrBadQuery{fmt.Errorf("season_type must be %s or %s", SeasonTypeRegular, SeasonTypePlayoffs)}
+	}
+	if from == "" {
+		var first sql.NullString
+		err := db.QueryRow(`SELECT MIN(`+Expr("season")+`) FROM playerstats
+			WHERE season_type = $1 AND NOT `+aggregateTeam, seasonType).Scan(&first)
+		if err != nil {
+			return LeagueAverages{}, fmt.Errorf("query failed: %w", err)
+		}
+		from = to
+		if first.Valid && first.String < to {
+			from = first.String
+		}
+	}
+
+	bands := ""
+	for _, b := range ageBands {
+		bands += fmt.Sprintf(", COALESCE(SUM(%s) FILTER (WHERE %s BETWEEN %d AND %d), 0)::bigint",
+			Expr("mp"), Expr("age"), b.lo, b.hi)
+	}
+	rows, err := db.Query(`SELECT season, COUNT(DISTINCT team),
+			COALESCE(SUM(`+Expr("mp")+`), 0)::bigint, COALESCE(SUM(`+Expr("pts")+`), 0)::bigint,
+			COALESCE(SUM(`+Expr("fg")+`), 0)::bigint, COALESCE(SUM(`+Expr("fga")+`), 0)::bigint,
+			COALESCE(SUM(`+Expr("fg3")+`), 0)::bigint, COALESCE(SUM(`+Expr("fg3a")+`), 0)::bigint,
+			COALESCE(SUM(`+Expr("ft")+`), 0)::bigint, COALESCE(SUM(`+Expr("fta")+`), 0)::bigint,
+			COALESCE(SUM(`+Expr("trb")+`), 0)::bigint, COALESCE(SUM(`+Expr("ast")+`), 0)::bigint,
+			COALESCE(SUM(`+Expr("tov")+`), 0)::bigint`+bands+`
+		FROM playerstats
+		WHERE season_type = $1 AND `+Expr("season")+` BETWEEN $2 AND $3 AND NOT `+aggregateTeam+`
+		GROUP BY season
+		ORDER BY season ASC`, seasonType, from, to)
+	if err != nil {
+		return LeagueAverages{}, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	avg := LeagueAverages{From: from, To: to, SeasonType: seasonType, Seasons: []LeagueSeason{}}
+	for rows.Next() {
+		var s LeagueSeason
+		var l Line
+		band := make([]int64, len(ageBands))
+		dest := []interface{}{&s.Season, &s.Teams, &l.MP, &l.PTS, &l.FG, &l.FGA, &l.FG3, &l.FG3A, &l.FT, &l.FTA, &l.TRB, &l.AST, &l.TOV}
+		for i := range band {
+			dest = append(dest, &band[i])
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return LeagueAverages{}, fmt.Errorf("scan failed: %w", err)
+		}
+		l.finish()
+
+		s.SeasonLabel = season.Label(s.Season)
+		if seasonType == SeasonTypeRegular {
+			year, _ := strconv.Atoi(s.Season)
+			s.TeamGames = s.Teams * season.FromEnd(year).Games()
+		} else {
+			s.TeamGames = int(float64(l.MP)/240 + 0.5)
+		}
+		if s.TeamGames > 0 {
+			per := func(n int) float64 { return round(float64(n)/float64(s.TeamGames), 1) }
+			s.PTS, s.FGA, s.FG3A, s.FTA = per(l.PTS), per(l.FGA), per(l.FG3A), per(l.FTA)
+			s.TRB, s.AST, s.TOV = per(l.TRB), per(l.AST), per(l.TOV)
+		}
+		s.FGPct, s.FG3Pct, s.FTPct = l.FGPct, l.FG3Pct, l.FTPct
+		s.FG3ARate = pct(l.FG3A, l.FGA)
+		s.FTRate = pct(l.FTA, l.FGA)
+
+		s.MinutesByAge = make(map[string]float64, len(ageBands))
+		for i, b := range ageBands {
+			if l.MP > 0 {
+				s.MinutesByAge[b.label] = round(float64(band[i])/float64(l.MP), 3)
+			}
+		}
+		avg.Seasons = append(avg.Seasons, s)
+	}
+	return avg, rows.Err()
+}
@@ -0,0 +1,243 @@
+package stats
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/umanchanda/NBA-API/filter"
+	"github.com/umanchanda/NBA-API/season"
+)
+
+const (
+	DefaultLimit = 50
+	MaxLimit     = 500
+)
+
+// defaultSort is the order /api/player has always used.
+var defaultSort = []SortKey{
+	{Column: "season", Desc: true},
+	{Column: "season_type"},
+	{Column: "name"},
+}
+
+// Row is one playerstats row projected onto a set of fields. It encodes as
+// a JSON object with the fields in the order they were requested, plus a
+// season_label ("2023-24") after season, and a context object when
+// AddContext has filled one in.
+type Row struct {
+	Fields  []string
+	Values  []string
+	Context map[string]*StatContext
+}
+
+// has reports whether field is one of the row's fields.
+func (r Row) has(field string) bool {
+	for _, f := range r.Fields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// Get returns the value of field, or "" if the row does not have it.
+func (r Row) Get(field string) string {
+	for i, f := range r.Fields {
+		if f == field {
+			return r.Values[i]
+		}
+	}
+	return ""
+}
+
+func (r Row) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, f := range r.Fields {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		k, _ := json.Marshal(f)
+		v, err := json.Marshal(r.Values[i])
+		if err != nil {
+			return nil, err
+		}
+		b.Write(k)
+		b.WriteByte(':')
+		b.Write(v)
+		if f == "season" {
+			label, _ := json.Marshal(season.Label(r.Values[i]))
+			b.WriteString(`,"season_label":`)
+			b.Write(label)
+		}
+	}
+	if r.Context != nil {
+		ctx, err := json.Marshal(r.Context)
+		if err != nil {
+			return nil, err
+		}
+		b.WriteString(`,"context":`)
+		b.Write(ctx)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+// selectList renders fields as a SELECT list with NULLs read as "".
+func selectList(fields []string) string {
+	cols := make([]string, len(fields))
+	for i, f := range fields {
+		cols[i] = "COALESCE(" + f + ", '')"
+	}
+	return strings.Join(cols, ", ")
+}
+
+// scanRows reads every result row into a Row over fields.
+func scanRows(rows *sql.Rows, fields []string) ([]Row, error) {
+	var out []Row
+	for rows.Next() {
+		row := Row{Fields: fields, Values: make([]string, len(fields))}
+		dest := make([]interface{}, len(row.Values))
+		for i := range row.Values {
+			dest[i] = &row.Values[i]
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("scan failed: %w", err)
+		}
+		out = append(out, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	return out, nil
+}
+
+// PlayerQuery describes a /api/player request.
+type PlayerQuery struct {
+	PlayerID   string
+	Name       string
+	Season     string
+	SeasonType string
+	Filter     *filter.Filter
+	Sort       []SortKey
+	Fields     []string
+	Limit      int
+	Cursor     string
+	Context    bool // measure each row against its season, see AddContext
+}
+
+// Page is the /api/player response envelope.
+type Page struct {
+	Season     *season.Season `json:"season,omitempty"`
+	Total      int            `json:"total"`
+	Count      int            `json:"count"`
+	Limit      int            `json:"limit"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+	Results    []Row          `json:"results"`
+}
+
+// cursors are opaque to clients but are just base64-encoded row offsets.
+func encodeCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte("o" + strconv.Itoa(offset)))
+}
+
+func decodeCursor(cursor string) (int, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || len(raw) < 2 || raw[0] != 'o' {
+		return 0, fmt.Errorf("invalid cursor")
+	}
+	offset, err := strconv.Atoi(string(raw[1:]))
+	if err != nil || offset < 0 {
+		return 0, fmt.Errorf("invalid cursor")
+	}
+	return offset, nil
+}
+
+// ErrBadQuery wraps errors caused by the request rather than the database.
+type ErrBadQuery struct{ Err error }
+
+func (e ErrBadQuery) Error() string { return e.Err.Error() }
+func (e ErrBadQuery) Unwrap() error { return e.Err }
+
+// Players runs q against playerstats and returns one page of results.
+func Players(db *sql.DB, q PlayerQuery) (Page, error) {
+	offset, err := decodeCursor(q.Cursor)
+	if err != nil {
+		return Page{}, ErrBadQuery{err}
+	}
+	if q.Limit <= 0 {
+		q.Limit = DefaultLimit
+	}
+	if len(q.Fields) == 0 {
+		q.Fields = Columns
+	}
+	if len(q.Sort) == 0 {
+		q.Sort = defaultSort
+	}
+
+	where := ` FROM playerstats WHERE LOWER(name) LIKE LOWER($1)`
+	args := []interface{}{"%" + q.Name + "%"}
+	if q.PlayerID != "" {
+		args = append(args, q.PlayerID)
+		where += fmt.Sprintf(" AND player_id = $%d", len(args))
+	}
+	if q.Season != "" {
+		args = append(args, q.Season)
+		where += fmt.Sprintf(" AND season = $%d", len(args))
+	}
+	if q.SeasonType != "" {
+		args = append(args, q.SeasonType)
+		where += fmt.Sprintf(" AND season_type = $%d", len(args))
+	}
+	if q.Filter != nil {
+		cond, filterArgs, err := q.Filter.SQL(ResolveColumn, len(args)+1)
+		if err != nil {
+			return Page{}, ErrBadQuery{err}
+		}
+		where += " AND " + cond
+		args = append(args, filterArgs...)
+	}
+
+	page := Page{Limit: q.Limit, Results: []Row{}}
+	if s, err := season.Parse(q.Season); err == nil {
+		page.Season = &s
+	}
+	if err := db.QueryRow("SELECT COUNT(*)"+where, args...).Scan(&page.Total); err != nil {
+		return Page{}, fmt.Errorf("counting rows: %w", err)
+	}
+
+	args = append(args, q.Limit, offset)
+	query := "SELECT " + selectList(q.Fields) + where + orderBy(q.Sort) +
+		fmt.Sprintf(" LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return Page{}, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	results, err := scanRows(rows, q.Fields)
+	if err != nil {
+		return Page{}, err
+	}
+	if q.Context {
+		if err := AddContext(db, results); err != nil {
+			return Page{}, err
+		}
+	}
+	page.Results = append(page.Results, results...)
+
+	page.Count = len(page.Results)
+	if next := offset + page.Count; next < page.Total {
+		page.NextCursor = encodeCursor(next)
+	}
+	return page, nil
+}
@@ -0,0 +1,124 @@
+package stats
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+
+	"github.com/lib/pq"
+
+	"github.com/umanchanda/NBA-API/franchise"
+	"github.com/umanchanda/NBA-API/season"
+)
+
+// RosterQuery describes a /api/team/{code}/roster request.
+type RosterQuery struct {
+	Code       string
+	Season     string // required unless Franchise is set
+	SeasonType string
+	Franchise  bool
+}
+
+// RosterPlayer is a player's line for one team in one season. Traded is
+// set when the player also played for another team that season.
+type RosterPlayer struct {
+	PlayerID    string `json:"player_id"`
+	Name        string `json:"name"`
+	Season      string `json:"season"`
+	SeasonLabel string `json:"season_label"`
+	Team        string `json:"team"`
+	Pos         string `json:"pos"`
+	Age         string `json:"age"`
+	Traded      bool   `json:"traded"`
+	Line
+}
+
+// Roster is the /api/team/{code}/roster response.
+type Roster struct {
+	Franchise   string         `json:"franchise"`
+	Teams       []string       `json:"teams"`
+	Season      string         `json:"season,omitempty"`
+	SeasonLabel string         `json:"season_label,omitempty"`
+	SeasonType  string         `json:"season_type"`
+	Players     []RosterPlayer `json:"players"`
+}
+
+// TeamRoster returns everyone who played for a team in a season, with their
+// totals for that team only. The code may be any the franchise has used:
+// for a single season it is resolved to the code in use that season
+// (OKC in 2005 means SEA), and with Franchise set every code is included, so
+// one request spans relocations.
+func TeamRoster(db *sql.DB, q RosterQuery) (Roster, error) {
+	if q.SeasonType == "" {
+		q.SeasonType = SeasonTypeRegular
+	}
+	f, ok := franchise.Lookup(q.Code)
+	if !ok {
+		return Roster{}, ErrBadQuery{fmt.Errorf("unknown team %q", q.Code)}
+	}
+
+	roster := Roster{Franchise: f.ID, Season: q.Season, SeasonType: q.SeasonType, Players: []RosterPlayer{}}
+	switch {
+	case q.Franchise:
+		roster.Teams = f.Codes()
+	case q.Season == "":
+		return Roster{}, ErrBadQuery{fmt.Errorf("season is required unless franchise=true")}
+	default:
+		year, _ := strconv.Atoi(q.Season)
+		era, ok := f.EraFor(year)
+		if !ok {
+			return Roster{}, ErrBadQuery{fmt.Errorf("%s did not play in %s", f.ID, season.Label(q.Season))}
+		}
+		roster.Teams = []string{era.Code}
+	}
+	if q.Season != "" {
+		roster.SeasonLabel = season.Label(q.Season)
+	}
+
+	query := `SELECT ` + selectList(Columns) + `,
+			EXISTS (SELECT 1 FROM playerstats t
+				WHERE t.player_id = p.player_id AND t.season = p.season
+				AND t.season_type = p.season_type AND ` + aggregateTeamOf("t") + `)
+		FROM playerstats p
+		WHERE team = ANY($1) AND season_type = $2`
+	args := []interface{}{pq.Array(roster.Teams), q.SeasonType}
+	if q.Season != "" {
+		args = append(args, q.Season)
+		query += " AND season = $3"
+	}
+	query += " ORDER BY season DESC, " + Expr("mp") + " DESC NULLS LAST, name"
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return Roster{}, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		row := Row{Fields: Columns, Values: make([]string, len(Columns))}
+		dest := make([]interface{}, 0, len(row.Values)+1)
+		for i := range row.Values {
+			dest = append(dest, &row.Values[i])
+		}
+		var traded bool
+		if err := rows.Scan(append(dest, &traded)...); err != nil {
+			return Roster{}, fmt.Errorf("scan failed: %w", err)
+		}
+
+		p := RosterPlayer{
+			PlayerID:    row.Get("player_id"),
+			Name:        row.Get("name"),
+			Season:      row.Get("season"),
+			SeasonLabel: season.Label(row.Get("season")),
+			Team:        row.Get("team"),
+			Pos:         row.Get("pos"),
+			Age:         row.Get("age"),
+			Traded:      traded,
+		}
+		p.add(row)
+		p.finish()
+		p.Seasons = 0
+		roster.Players = append(roster.Players, p)
+	}
+	return roster, rows.Err()
+}
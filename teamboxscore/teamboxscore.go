@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"regexp"
 	"strings"
 
 	"github.com/PuerkitoBio/goquery"
@@ -48,11 +49,13 @@ var teamCodes = map[string]string{
 
 // TeamBoxScore is a team box score
 type TeamBoxScore struct {
+	GameID           string   `json:"game_id,omitempty"`
 	LosingTeam       string   `json:"losing_team,omitempty"`
 	WinningTeam      string   `json:"winning_team,omitempty"`
 	LosingTeamScore  string   `json:"losing_team_score,omitempty"`
 	WinningTeamScore string   `json:"winning_team_score,omitempty"`
 	Status           string   `json:"status,omitempty"`
+	AwayTeam         string   `json:"away_team,omitempty"`
 	HomeTeam         string   `json:"home_team,omitempty"`
 	AwayQuarterScore []string `json:"away_quarter_score,omitempty"`
 	HomeQuarterScore []string `json:"home_quarter_score,omitempty"`
@@ -65,16 +68,19 @@ type AllTeamBoxScore struct {
 	BoxScores []TeamBoxScore `json:"box_scores,omitempty"`
 }
 
-func ExtractBoxScore(month, day, year string) (string, error) {
+var boxScoreHref = regexp.MustCompile(`/boxscores/(\w+)\.html`)
+
+// Scores scrapes every game on the daily scores page for a date.
+func Scores(month, day, year string) ([]TeamBoxScore, error) {
 	url := baseURL + "/boxscores/?month=" + month + "&day=" + day + "&year=" + year
 	html, err := fetch.HTML(url)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(html))
 	if err != nil {
-		return "", fmt.Errorf("parsing HTML: %w", err)
+		return nil, fmt.Errorf("parsing HTML: %w", err)
 	}
 
 	gs := doc.Find(".game_summary")
@@ -90,6 +96,12 @@ func ExtractBoxScore(month, day, year string) (string, error) {
 		winningTeam := strings.TrimSpace(table0.Find("tbody .winner td a").First().Text())
 		winningTeamScore := strings.TrimSpace(table0.Find("tbody .winner td.right:not(.gamelink)").First().Text())
 		status := strings.TrimSpace(game.Find("tbody .gamelink a").First().Text())
+		var gameID string
+		if href, ok := game.Find("tbody .gamelink a").First().Attr("href"); ok {
+			if m := boxScoreHref.FindStringSubmatch(href); m != nil {
+				gameID = m[1]
+			}
+		}
 
 		awayTeam := strings.TrimSpace(table1.Find("tbody tr").Eq(0).Find("td a").First().Text())
 		homeTeam := strings.TrimSpace(table1.Find("tbody tr").Eq(1).Find("td a").First().Text())
@@ -110,11 +122,13 @@ func ExtractBoxScore(month, day, year string) (string, error) {
 		playerBreakdown := "/playerstats/" + year + "/" + month + "/" + day + "/" + awayTeamCode + "/" + homeTeamCode
 
 		scoresArray = append(scoresArray, TeamBoxScore{
+			GameID:           gameID,
 			LosingTeam:       losingTeam,
 			WinningTeam:      winningTeam,
 			LosingTeamScore:  losingTeamScore,
 			WinningTeamScore: winningTeamScore,
 			Status:           status,
+			AwayTeam:         awayTeam,
 			HomeTeam:         homeTeam,
 			AwayQuarterScore: awayScores,
 			HomeQuarterScore: homeScores,
@@ -123,7 +137,15 @@ func ExtractBoxScore(month, day, year string) (string, error) {
 		})
 	}
 
-	result, err := json.Marshal(AllTeamBoxScore{BoxScores: scoresArray})
+	return scoresArray, nil
+}
+
+func ExtractBoxScore(month, day, year string) (string, error) {
+	scores, err := Scores(month, day, year)
+	if err != nil {
+		return "", err
+	}
+	result, err := json.Marshal(AllTeamBoxScore{BoxScores: scores})
 	if err != nil {
 		return "", fmt.Errorf("encoding JSON: %w", err)
 	}
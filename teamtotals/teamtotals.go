@@ -68,6 +68,14 @@ func extractTeamTotals(sel *goquery.Selection, team string) TeamTotals {
 	}
 }
 
+// Totals reads both teams' totals from a parsed box score page.
+func Totals(doc *goquery.Document, awayTeam, homeTeam string) []TeamTotals {
+	return []TeamTotals{
+		extractTeamTotals(doc.Find("#box-"+awayTeam+"-game-basic tfoot tr"), awayTeam),
+		extractTeamTotals(doc.Find("#box-"+homeTeam+"-game-basic tfoot tr"), homeTeam),
+	}
+}
+
 func ExtractGameSummary(month, day, year, awayTeam, homeTeam string) (string, error) {
 	url := baseURL + "/boxscores/" + year + month + day + "0" + homeTeam + ".html"
 	html, err := fetch.HTML(url)
@@ -80,12 +88,7 @@ func ExtractGameSummary(month, day, year, awayTeam, homeTeam string) (string, er
 		return "", fmt.Errorf("parsing HTML: %w", err)
 	}
 
-	boxScores := []TeamTotals{
-		extractTeamTotals(doc.Find("#box-"+awayTeam+"-game-basic tfoot tr"), awayTeam),
-		extractTeamTotals(doc.Find("#box-"+homeTeam+"-game-basic tfoot tr"), homeTeam),
-	}
-
-	result, err := json.Marshal(boxScores)
+	result, err := json.Marshal(Totals(doc, awayTeam, homeTeam))
 	if err != nil {
 		return "", fmt.Errorf("encoding JSON: %w", err)
 	}
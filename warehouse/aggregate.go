@@ -0,0 +1,96 @@
+package warehouse
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+
+	"github.com/lib/pq"
+)
+
+// Aggregate sums a team's lines over several games, such as every meeting
+// between two teams or the games of a playoff series.
+type Aggregate struct {
+	Games   int      `json:"games"`
+	Totals  Line     `json:"totals"`
+	PerGame PerGame  `json:"per_game"`
+	FGPct   *float64 `json:"fg_pct"`
+	FG3Pct  *float64 `json:"fg3_pct"`
+	FTPct   *float64 `json:"ft_pct"`
+}
+
+// PerGame is an Aggregate's averages.
+type PerGame struct {
+	PTS  float64 `json:"pts"`
+	FGA  float64 `json:"fga"`
+	FG3A float64 `json:"fg3a"`
+	FTA  float64 `json:"fta"`
+	ORB  float64 `json:"orb"`
+	TRB  float64 `json:"trb"`
+	AST  float64 `json:"ast"`
+	STL  float64 `json:"stl"`
+	BLK  float64 `json:"blk"`
+	TOV  float64 `json:"tov"`
+}
+
+// Add counts one game's line.
+func (a *Aggregate) Add(l Line) {
+	a.Games++
+	t := &a.Totals
+	t.FG, t.FGA, t.FG3, t.FG3A, t.FT, t.FTA = t.FG+l.FG, t.FGA+l.FGA, t.FG3+l.FG3, t.FG3A+l.FG3A, t.FT+l.FT, t.FTA+l.FTA
+	t.ORB, t.DRB, t.TRB, t.AST, t.STL = t.ORB+l.ORB, t.DRB+l.DRB, t.TRB+l.TRB, t.AST+l.AST, t.STL+l.STL
+	t.BLK, t.TOV, t.PF, t.PTS = t.BLK+l.BLK, t.TOV+l.TOV, t.PF+l.PF, t.PTS+l.PTS
+}
+
+// Finish computes the averages and percentages once every game is added.
+func (a *Aggregate) Finish() {
+	if a.Games == 0 {
+		return
+	}
+	per := func(n int) float64 {
+		return math.Round(float64(n)/float64(a.Games)*10) / 10
+	}
+	t := a.Totals
+	a.PerGame = PerGame{
+		PTS: per(t.PTS), FGA: per(t.FGA), FG3A: per(t.FG3A), FTA: per(t.FTA),
+		ORB: per(t.ORB), TRB: per(t.TRB), AST: per(t.AST), STL: per(t.STL), BLK: per(t.BLK), TOV: per(t.TOV),
+	}
+	a.FGPct = ratio(t.FG, t.FGA)
+	a.FG3Pct = ratio(t.FG3, t.FG3A)
+	a.FTPct = ratio(t.FT, t.FTA)
+}
+
+func ratio(makes, attempts int) *float64 {
+	if attempts == 0 {
+		return nil
+	}
+	v := math.Round(float64(makes)/float64(attempts)*1000) / 1000
+	return &v
+}
+
+// TeamLines returns the stored team totals of each of gameIDs, keyed by
+// game id. Games that are not stored are missing from the map.
+func TeamLines(db *sql.DB, gameIDs []string) (map[string][]TeamLine, error) {
+	rows, err := db.Query(`SELECT game_id, team, home, mp, `+lineColumns+`
+		FROM game_team_stats
+		WHERE game_id = ANY($1)
+		ORDER BY game_id, home`, pq.Array(gameIDs))
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	lines := make(map[string][]TeamLine)
+	for rows.Next() {
+		var id string
+		var t TeamLine
+		if err := rows.Scan(append([]interface{}{&id, &t.Team, &t.Home, &t.MP}, t.Line.dest()...)...); err != nil {
+			return nil, fmt.Errorf("scan failed: %w", err)
+		}
+		lines[id] = append(lines[id], t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	return lines, nil
+}
@@ -0,0 +1,246 @@
+package warehouse
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/umanchanda/NBA-API/franchise"
+	"github.com/umanchanda/NBA-API/playertotals"
+	"github.com/umanchanda/NBA-API/season"
+	"github.com/umanchanda/NBA-API/teamboxscore"
+	"github.com/umanchanda/NBA-API/teamtotals"
+)
+
+// ErrNotStored is returned when a game or date has not been backfilled, so
+// callers can fall back to scraping.
+var ErrNotStored = fmt.Errorf("not stored")
+
+// GameID returns the basketball-reference id of the game home hosted on
+// date. home may be any code the franchise has used or an alias such as
+// BKN; it is resolved to the code in use that season.
+func GameID(date time.Time, home string) string {
+	code := strings.ToUpper(home)
+	if f, ok := franchise.Lookup(home); ok {
+		if e, ok := f.EraFor(season.FromDate(date).End); ok {
+			code = e.Code
+		}
+	}
+	return date.Format("20060102") + "0" + code
+}
+
+// pct formats a percentage the way basketball-reference box scores do:
+// ".456", "1.000", or blank without attempts.
+func pct(makes, attempts int) string {
+	if attempts == 0 {
+		return ""
+	}
+	return strings.TrimPrefix(fmt.Sprintf("%.3f", float64(makes)/float64(attempts)), "0")
+}
+
+func itoa(n int) string {
+	return strconv.Itoa(n)
+}
+
+// Scores returns the stored games on date in the form the daily scores page
+// is scraped into, or ErrNotStored if none are stored.
+func Scores(db *sql.DB, date time.Time) ([]teamboxscore.TeamBoxScore, error) {
+	rows, err := db.Query(`SELECT game_id, away, home, away_name, home_name, away_pts, home_pts,
+			away_periods, home_periods, status
+		FROM games
+		WHERE date = $1
+		ORDER BY game_id`, date)
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	y, m, d := date.Format("2006"), date.Format("01"), date.Format("02")
+	var scores []teamboxscore.TeamBoxScore
+	for rows.Next() {
+		var id, away, home, awayName, homeName, status string
+		var awayPts, homePts int
+		var awayPeriods, homePeriods pq.Int64Array
+		err := rows.Scan(&id, &away, &home, &awayName, &homeName, &awayPts, &homePts,
+			&awayPeriods, &homePeriods, &status)
+		if err != nil {
+			return nil, fmt.Errorf("scan failed: %w", err)
+		}
+
+		s := teamboxscore.TeamBoxScore{
+			GameID:          id,
+			Status:          status,
+			AwayTeam:        awayName,
+			HomeTeam:        homeName,
+			ScoreBreakdown:  "/teamstats/" + y + "/" + m + "/" + d + "/" + away + "/" + home,
+			PlayerBreakdown: "/playerstats/" + y + "/" + m + "/" + d + "/" + away + "/" + home,
+		}
+		s.WinningTeam, s.WinningTeamScore = awayName, itoa(awayPts)
+		s.LosingTeam, s.LosingTeamScore = homeName, itoa(homePts)
+		if homePts > awayPts {
+			s.WinningTeam, s.WinningTeamScore, s.LosingTeam, s.LosingTeamScore =
+				s.LosingTeam, s.LosingTeamScore, s.WinningTeam, s.WinningTeamScore
+		}
+		for _, p := range awayPeriods {
+			s.AwayQuarterScore = append(s.AwayQuarterScore, itoa(int(p)))
+		}
+		for _, p := range homePeriods {
+			s.HomeQuarterScore = append(s.HomeQuarterScore, itoa(int(p)))
+		}
+		scores = append(scores, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	if len(scores) == 0 {
+		return nil, ErrNotStored
+	}
+	return scores, nil
+}
+
+// lineColumns are the box score columns read into a Line, in scan order.
+const lineColumns = `fg, fga, fg3, fg3a, ft, fta, orb, drb, trb, ast, stl, blk, tov, pf, pts`
+
+func (l *Line) dest() []interface{} {
+	return []interface{}{&l.FG, &l.FGA, &l.FG3, &l.FG3A, &l.FT, &l.FTA,
+		&l.ORB, &l.DRB, &l.TRB, &l.AST, &l.STL, &l.BLK, &l.TOV, &l.PF, &l.PTS}
+}
+
+// TeamTotals returns a stored game's team totals, away team first, as the
+// box score page is scraped into.
+func TeamTotals(db *sql.DB, gameID string) ([]teamtotals.TeamTotals, error) {
+	rows, err := db.Query(`SELECT team, mp, `+lineColumns+`
+		FROM game_team_stats
+		WHERE game_id = $1
+		ORDER BY home`, gameID)
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	var totals []teamtotals.TeamTotals
+	for rows.Next() {
+		var t TeamLine
+		if err := rows.Scan(append([]interface{}{&t.Team, &t.MP}, t.Line.dest()...)...); err != nil {
+			return nil, fmt.Errorf("scan failed: %w", err)
+		}
+		totals = append(totals, teamtotals.TeamTotals{
+			Team:                 t.Team,
+			MinutesPlayed:        itoa(t.MP),
+			FieldGoals:           itoa(t.FG),
+			FieldGoalsAttempted:  itoa(t.FGA),
+			FieldGoalPercentage:  pct(t.FG, t.FGA),
+			ThreePoint:           itoa(t.FG3),
+			ThreePointAttempted:  itoa(t.FG3A),
+			ThreePointPercentage: pct(t.FG3, t.FG3A),
+			FreeThrows:           itoa(t.FT),
+			FreeThrowsAttempted:  itoa(t.FTA),
+			FreeThrowPercentage:  pct(t.FT, t.FTA),
+			OffensiveRebounds:    itoa(t.ORB),
+			DefensiveRebounds:    itoa(t.DRB),
+			TotalRebounds:        itoa(t.TRB),
+			Assists:              itoa(t.AST),
+			Steals:               itoa(t.STL),
+			Blocks:               itoa(t.BLK),
+			Turnovers:            itoa(t.TOV),
+			PersonalFouls:        itoa(t.PF),
+			Points:               itoa(t.PTS),
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	if len(totals) == 0 {
+		return nil, ErrNotStored
+	}
+	return totals, nil
+}
+
+// PlayerTotals returns a stored game's player lines, away team first, as
+// the box score page is scraped into. Players who did not play are not
+// stored.
+func PlayerTotals(db *sql.DB, gameID string) ([]playertotals.PlayerTotalsTeam, error) {
+	rows, err := db.Query(`SELECT p.team, p.player_id, p.name, p.starter, p.seconds, p.plus_minus, `+
+		prefixed("p", lineColumns)+`
+		FROM game_player_stats p
+		JOIN game_team_stats t ON t.game_id = p.game_id AND t.team = p.team
+		WHERE p.game_id = $1
+		ORDER BY t.home, p.starter DESC, p.seconds DESC`, gameID)
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	var teams []playertotals.PlayerTotalsTeam
+	var current string
+	for rows.Next() {
+		var p PlayerLine
+		var plusMinus sql.NullInt64
+		dest := append([]interface{}{&p.Team, &p.PlayerID, &p.Name, &p.Starter, &p.Seconds, &plusMinus}, p.Line.dest()...)
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("scan failed: %w", err)
+		}
+		if p.Team != current {
+			teams = append(teams, playertotals.PlayerTotalsTeam{})
+			current = p.Team
+		}
+
+		line := playertotals.PlayerTotals{
+			Team:                 p.Team,
+			PlayerID:             p.PlayerID,
+			Name:                 p.Name,
+			MinutesPlayed:        fmt.Sprintf("%d:%02d", p.Seconds/60, p.Seconds%60),
+			FieldGoals:           itoa(p.FG),
+			FieldGoalsAttempted:  itoa(p.FGA),
+			FieldGoalPercentage:  pct(p.FG, p.FGA),
+			ThreePoint:           itoa(p.FG3),
+			ThreePointAttempted:  itoa(p.FG3A),
+			ThreePointPercentage: pct(p.FG3, p.FG3A),
+			FreeThrows:           itoa(p.FT),
+			FreeThrowsAttempted:  itoa(p.FTA),
+			FreeThrowPercentage:  pct(p.FT, p.FTA),
+			OffensiveRebounds:    itoa(p.ORB),
+			DefensiveRebounds:    itoa(p.DRB),
+			TotalRebounds:        itoa(p.TRB),
+			Assists:              itoa(p.AST),
+			Steals:               itoa(p.STL),
+			Blocks:               itoa(p.BLK),
+			Turnovers:            itoa(p.TOV),
+			PersonalFouls:        itoa(p.PF),
+			Points:               itoa(p.PTS),
+		}
+		switch {
+		case !plusMinus.Valid:
+		case plusMinus.Int64 == 0:
+			line.PlusMinus = "0"
+		default:
+			line.PlusMinus = fmt.Sprintf("%+d", plusMinus.Int64)
+		}
+		t := &teams[len(teams)-1]
+		if p.Starter {
+			t.Starters = append(t.Starters, line)
+		} else {
+			t.Reserves = append(t.Reserves, line)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	if len(teams) == 0 {
+		return nil, ErrNotStored
+	}
+	return teams, nil
+}
+
+// prefixed qualifies every column in a comma-separated list with alias.
+func prefixed(alias, columns string) string {
+	cols := strings.Split(columns, ", ")
+	for i, c := range cols {
+		cols[i] = alias + "." + c
+	}
+	return strings.Join(cols, ", ")
+}
@@ -0,0 +1,148 @@
+package warehouse
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+
+	"github.com/umanchanda/NBA-API/internal/fetch"
+	"github.com/umanchanda/NBA-API/playertotals"
+	"github.com/umanchanda/NBA-API/season"
+	"github.com/umanchanda/NBA-API/stats"
+	"github.com/umanchanda/NBA-API/teamboxscore"
+	"github.com/umanchanda/NBA-API/teamtotals"
+)
+
+const baseURL = "https://www.basketball-reference.com"
+
+var (
+	boxTable = regexp.MustCompile(`^box-(\w+)-game-basic$`)
+	// Playoff box score headings read "2024 NBA Finals Game 5: ...".
+	playoffHeading = regexp.MustCompile(`\bGame \d+:`)
+	playInHeading  = regexp.MustCompile(`(?i)\bplay-in\b`)
+)
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(strings.TrimSpace(s))
+	return n
+}
+
+// seconds converts a "35:12" minutes played cell to seconds.
+func seconds(mp string) int {
+	m, s, _ := strings.Cut(strings.TrimSpace(mp), ":")
+	return atoi(m)*60 + atoi(s)
+}
+
+// Scrape fetches the box score page of a game listed on the daily scores
+// page for date and reads both teams' totals and every player who played.
+func Scrape(date time.Time, score teamboxscore.TeamBoxScore) (Game, error) {
+	id := score.GameID
+	if len(id) != 12 {
+		return Game{}, fmt.Errorf("game %q has no box score id", score.HomeTeam)
+	}
+	html, err := fetch.HTML(baseURL + "/boxscores/" + id + ".html")
+	if err != nil {
+		return Game{}, err
+	}
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(html))
+	if err != nil {
+		return Game{}, fmt.Errorf("parsing HTML: %w", err)
+	}
+
+	g := Game{
+		ID:         id,
+		Date:       date,
+		Season:     season.FromDate(date).Key(),
+		SeasonType: stats.SeasonTypeRegular,
+		Home:       id[9:],
+		AwayName:   score.AwayTeam,
+		HomeName:   score.HomeTeam,
+		Status:     score.Status,
+	}
+	doc.Find("table[id$='-game-basic']").Each(func(_ int, t *goquery.Selection) {
+		tid, _ := t.Attr("id")
+		if m := boxTable.FindStringSubmatch(tid); m != nil && m[1] != g.Home && g.Away == "" {
+			g.Away = m[1]
+		}
+	})
+	if g.Away == "" {
+		return Game{}, fmt.Errorf("%s: no box score table for the away team", id)
+	}
+
+	heading := doc.Find("#content h1").First().Text()
+	switch {
+	case playInHeading.MatchString(heading):
+		g.SeasonType = SeasonTypePlayIn
+	case playoffHeading.MatchString(heading):
+		g.SeasonType = stats.SeasonTypePlayoffs
+	}
+
+	for _, s := range score.AwayQuarterScore {
+		g.AwayPeriods = append(g.AwayPeriods, atoi(s))
+	}
+	for _, s := range score.HomeQuarterScore {
+		g.HomePeriods = append(g.HomePeriods, atoi(s))
+	}
+
+	for i, t := range teamtotals.Totals(doc, g.Away, g.Home) {
+		line := TeamLine{
+			Team: t.Team,
+			Home: i == 1,
+			MP:   atoi(t.MinutesPlayed),
+			Line: Line{
+				FG: atoi(t.FieldGoals), FGA: atoi(t.FieldGoalsAttempted),
+				FG3: atoi(t.ThreePoint), FG3A: atoi(t.ThreePointAttempted),
+				FT: atoi(t.FreeThrows), FTA: atoi(t.FreeThrowsAttempted),
+				ORB: atoi(t.OffensiveRebounds), DRB: atoi(t.DefensiveRebounds), TRB: atoi(t.TotalRebounds),
+				AST: atoi(t.Assists), STL: atoi(t.Steals), BLK: atoi(t.Blocks),
+				TOV: atoi(t.Turnovers), PF: atoi(t.PersonalFouls), PTS: atoi(t.Points),
+			},
+		}
+		if line.Home {
+			g.HomePts = line.PTS
+		} else {
+			g.AwayPts = line.PTS
+		}
+		g.Teams = append(g.Teams, line)
+	}
+
+	for _, team := range playertotals.Players(doc, g.Away, g.Home) {
+		for i, p := range append(team.Starters, team.Reserves...) {
+			// Players who did not play have a reason in place of minutes.
+			if !strings.Contains(p.MinutesPlayed, ":") {
+				continue
+			}
+			line := PlayerLine{
+				Team:     p.Team,
+				PlayerID: p.PlayerID,
+				Name:     strings.TrimSpace(p.Name),
+				Starter:  i < len(team.Starters),
+				Seconds:  seconds(p.MinutesPlayed),
+				Line: Line{
+					FG: atoi(p.FieldGoals), FGA: atoi(p.FieldGoalsAttempted),
+					FG3: atoi(p.ThreePoint), FG3A: atoi(p.ThreePointAttempted),
+					FT: atoi(p.FreeThrows), FTA: atoi(p.FreeThrowsAttempted),
+					ORB: atoi(p.OffensiveRebounds), DRB: atoi(p.DefensiveRebounds), TRB: atoi(p.TotalRebounds),
+					AST: atoi(p.Assists), STL: atoi(p.Steals), BLK: atoi(p.Blocks),
+					TOV: atoi(p.Turnovers), PF: atoi(p.PersonalFouls), PTS: atoi(p.Points),
+				},
+			}
+			if line.PlayerID == "" {
+				line.PlayerID = line.Name
+			}
+			if pm, err := strconv.Atoi(strings.TrimPrefix(strings.TrimSpace(p.PlusMinus), "+")); err == nil {
+				line.PlusMinus = &pm
+			}
+			g.Players = append(g.Players, line)
+		}
+	}
+	if g.AwayPts == 0 && g.HomePts == 0 {
+		return Game{}, fmt.Errorf("%s: no final score", id)
+	}
+	return g, nil
+}
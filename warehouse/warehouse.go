@@ -0,0 +1,206 @@
+// Package warehouse stores every game's box score so the /boxscore routes,
+// standings and ratings can be served without scraping basketball-reference.
+package warehouse
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// SeasonTypePlayIn marks play-in tournament games, which count toward
+// neither the regular season nor the playoffs. The other season types are
+// the ones used by playerstats.
+const SeasonTypePlayIn = "play_in"
+
+// Game is one stored game with both teams' totals and every player who
+// played.
+type Game struct {
+	ID          string
+	Date        time.Time
+	Season      string
+	SeasonType  string
+	Away        string // team codes as basketball-reference used them that season
+	Home        string
+	AwayName    string // as shown on the daily scores page, e.g. "LA Lakers"
+	HomeName    string
+	AwayPts     int
+	HomePts     int
+	AwayPeriods []int // points per quarter, then per overtime
+	HomePeriods []int
+	Status      string // "Final", "Final/OT", ...
+	Teams       []TeamLine
+	Players     []PlayerLine
+}
+
+// Line is a basic box score line.
+type Line struct {
+	FG   int `json:"fg"`
+	FGA  int `json:"fga"`
+	FG3  int `json:"fg3"`
+	FG3A int `json:"fg3a"`
+	FT   int `json:"ft"`
+	FTA  int `json:"fta"`
+	ORB  int `json:"orb"`
+	DRB  int `json:"drb"`
+	TRB  int `json:"trb"`
+	AST  int `json:"ast"`
+	STL  int `json:"stl"`
+	BLK  int `json:"blk"`
+	TOV  int `json:"tov"`
+	PF   int `json:"pf"`
+	PTS  int `json:"pts"`
+}
+
+// TeamLine is one team's totals in a game. MP is player minutes, 240 for a
+// game without overtime.
+type TeamLine struct {
+	Team string
+	Home bool
+	MP   int
+	Line
+}
+
+// PlayerLine is one player's line in a game.
+type PlayerLine struct {
+	Team      string
+	PlayerID  string
+	Name      string
+	Starter   bool
+	Seconds   int
+	PlusMinus *int
+	Line
+}
+
+// CreateTables creates the games, game_team_stats and game_player_stats
+// tables if they do not exist.
+func CreateTables(db *sql.DB) error {
+	stmts := []string{
+		`CREATE TABLE IF NOT EXISTS games (
+			game_id      TEXT PRIMARY KEY,
+			date         DATE NOT NULL,
+			season       TEXT NOT NULL,
+			season_type  TEXT NOT NULL,
+			away         TEXT NOT NULL,
+			home         TEXT NOT NULL,
+			away_name    TEXT NOT NULL,
+			home_name    TEXT NOT NULL,
+			away_pts     INTEGER NOT NULL,
+			home_pts     INTEGER NOT NULL,
+			away_periods INTEGER[] NOT NULL,
+			home_periods INTEGER[] NOT NULL,
+			status       TEXT NOT NULL
+		)`,
+		`CREATE TABLE IF NOT EXISTS game_team_stats (
+			game_id TEXT NOT NULL REFERENCES games (game_id) ON DELETE CASCADE,
+			team    TEXT NOT NULL,
+			home    BOOLEAN NOT NULL,
+			mp      INTEGER NOT NULL,
+			fg      INTEGER NOT NULL,
+			fga     INTEGER NOT NULL,
+			fg3     INTEGER NOT NULL,
+			fg3a    INTEGER NOT NULL,
+			ft      INTEGER NOT NULL,
+			fta     INTEGER NOT NULL,
+			orb     INTEGER NOT NULL,
+			drb     INTEGER NOT NULL,
+			trb     INTEGER NOT NULL,
+			ast     INTEGER NOT NULL,
+			stl     INTEGER NOT NULL,
+			blk     INTEGER NOT NULL,
+			tov     INTEGER NOT NULL,
+			pf      INTEGER NOT NULL,
+			pts     INTEGER NOT NULL,
+			PRIMARY KEY (game_id, team)
+		)`,
+		`CREATE TABLE IF NOT EXISTS game_player_stats (
+			game_id    TEXT NOT NULL REFERENCES games (game_id) ON DELETE CASCADE,
+			team       TEXT NOT NULL,
+			player_id  TEXT NOT NULL,
+			name       TEXT NOT NULL,
+			starter    BOOLEAN NOT NULL,
+			seconds    INTEGER NOT NULL,
+			fg         INTEGER NOT NULL,
+			fga        INTEGER NOT NULL,
+			fg3        INTEGER NOT NULL,
+			fg3a       INTEGER NOT NULL,
+			ft         INTEGER NOT NULL,
+			fta        INTEGER NOT NULL,
+			orb        INTEGER NOT NULL,
+			drb        INTEGER NOT NULL,
+			trb        INTEGER NOT NULL,
+			ast        INTEGER NOT NULL,
+			stl        INTEGER NOT NULL,
+			blk        INTEGER NOT NULL,
+			tov        INTEGER NOT NULL,
+			pf         INTEGER NOT NULL,
+			pts        INTEGER NOT NULL,
+			plus_minus INTEGER,
+			PRIMARY KEY (game_id, player_id)
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_games_date ON games (date)`,
+		`CREATE INDEX IF NOT EXISTS idx_games_season ON games (season, season_type)`,
+		`CREATE INDEX IF NOT EXISTS idx_game_player_stats_player ON game_player_stats (player_id)`,
+	}
+	for _, stmt := range stmts {
+		if _, err := db.Exec(stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Exists reports whether a game has been stored.
+func Exists(db *sql.DB, gameID string) (bool, error) {
+	var n int
+	err := db.QueryRow(`SELECT COUNT(*) FROM games WHERE game_id = $1`, gameID).Scan(&n)
+	return n > 0, err
+}
+
+// Store saves a game, replacing it if it was stored before.
+func Store(db *sql.DB, g Game) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM games WHERE game_id = $1`, g.ID); err != nil {
+		return fmt.Errorf("replacing %s: %w", g.ID, err)
+	}
+	_, err = tx.Exec(`INSERT INTO games (
+			game_id, date, season, season_type, away, home, away_name, home_name,
+			away_pts, home_pts, away_periods, home_periods, status
+		) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13)`,
+		g.ID, g.Date, g.Season, g.SeasonType, g.Away, g.Home, g.AwayName, g.HomeName,
+		g.AwayPts, g.HomePts, pq.Array(g.AwayPeriods), pq.Array(g.HomePeriods), g.Status)
+	if err != nil {
+		return fmt.Errorf("insert failed for %s: %w", g.ID, err)
+	}
+
+	for _, t := range g.Teams {
+		_, err := tx.Exec(`INSERT INTO game_team_stats (
+				game_id, team, home, mp, fg, fga, fg3, fg3a, ft, fta,
+				orb, drb, trb, ast, stl, blk, tov, pf, pts
+			) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18,$19)`,
+			g.ID, t.Team, t.Home, t.MP, t.FG, t.FGA, t.FG3, t.FG3A, t.FT, t.FTA,
+			t.ORB, t.DRB, t.TRB, t.AST, t.STL, t.BLK, t.TOV, t.PF, t.PTS)
+		if err != nil {
+			return fmt.Errorf("insert failed for %s %s: %w", g.ID, t.Team, err)
+		}
+	}
+	for _, p := range g.Players {
+		_, err := tx.Exec(`INSERT INTO game_player_stats (
+				game_id, team, player_id, name, starter, seconds, fg, fga, fg3, fg3a, ft, fta,
+				orb, drb, trb, ast, stl, blk, tov, pf, pts, plus_minus
+			) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18,$19,$20,$21,$22)`,
+			g.ID, p.Team, p.PlayerID, p.Name, p.Starter, p.Seconds, p.FG, p.FGA, p.FG3, p.FG3A, p.FT, p.FTA,
+			p.ORB, p.DRB, p.TRB, p.AST, p.STL, p.BLK, p.TOV, p.PF, p.PTS, p.PlusMinus)
+		if err != nil {
+			return fmt.Errorf("insert failed for %s %s: %w", g.ID, p.Name, err)
+		}
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,111 @@
+package winprob
+
+import (
+	"errors"
+	"math"
+)
+
+// Sample is one play of a finished game and how the game ended.
+type Sample struct {
+	State
+	HomeWon bool
+}
+
+// ridge is a small L2 penalty that keeps the fit stable when a feature
+// barely varies, e.g. fitting a single game.
+const ridge = 1e-3
+
+// Fit fits a model to samples by Newton's method. Decided states, with no
+// time left, are left out since Predict doesn't use the model for them.
+func Fit(samples []Sample) (Model, error) {
+	var w [5]float64
+	n := 0
+	for iter := 0; iter < 50; iter++ {
+		var grad [5]float64
+		var hess [5][5]float64
+		n = 0
+		for _, s := range samples {
+			if s.Left <= 0 && s.Margin != 0 {
+				continue
+			}
+			n++
+			x := s.features()
+			var z float64
+			for i := range x {
+				z += w[i] * x[i]
+			}
+			p := sigmoid(z)
+			y := 0.0
+			if s.HomeWon {
+				y = 1
+			}
+			for i := range x {
+				grad[i] += (y - p) * x[i]
+				for j := range x {
+					hess[i][j] += p * (1 - p) * x[i] * x[j]
+				}
+			}
+		}
+		if n == 0 {
+			return Model{}, errors.New("no samples to fit")
+		}
+		for i := range w {
+			grad[i] -= ridge * w[i]
+			hess[i][i] += ridge
+		}
+		step, ok := solve(hess, grad)
+		if !ok {
+			return Model{}, errors.New("fit did not converge")
+		}
+		var size float64
+		for i := range w {
+			w[i] += step[i]
+			size = math.Max(size, math.Abs(step[i]))
+		}
+		if size < 1e-8 {
+			break
+		}
+	}
+	return Model{
+		Events:           n,
+		Intercept:        w[0],
+		Margin:           w[1],
+		ScaledMargin:     w[2],
+		ScaledPossession: w[3],
+		Pregame:          w[4],
+	}, nil
+}
+
+// solve solves a x = b by Gaussian elimination with partial pivoting.
+func solve(a [5][5]float64, b [5]float64) ([5]float64, bool) {
+	const n = 5
+	for c := 0; c < n; c++ {
+		pivot := c
+		for r := c + 1; r < n; r++ {
+			if math.Abs(a[r][c]) > math.Abs(a[pivot][c]) {
+				pivot = r
+			}
+		}
+		if math.Abs(a[pivot][c]) < 1e-12 {
+			return b, false
+		}
+		a[c], a[pivot] = a[pivot], a[c]
+		b[c], b[pivot] = b[pivot], b[c]
+		for r := c + 1; r < n; r++ {
+			f := a[r][c] / a[c][c]
+			for k := c; k < n; k++ {
+				a[r][k] -= f * a[c][k]
+			}
+			b[r] -= f * b[c]
+		}
+	}
+	var x [5]float64
+	for r := n - 1; r >= 0; r-- {
+		v := b[r]
+		for k := r + 1; k < n; k++ {
+			v -= a[r][k] * x[k]
+		}
+		x[r] = v / a[r][r]
+	}
+	return x, true
+}
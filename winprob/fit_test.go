@@ -0,0 +1,140 @@
+package winprob
+
+import (
+	"math"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/umanchanda/NBA-API/pbp"
+)
+
+func TestFitRecoversCoefficients(t *testing.T) {
+	want := Model{Intercept: 0.2, Margin: 0.05, ScaledMargin: 0.7, ScaledPossession: 0.6, Pregame: 1.2}
+	rng := rand.New(rand.NewPCG(1, 2))
+	var samples []Sample
+	for i := 0; i < 200000; i++ {
+		s := State{
+			Margin:     rng.IntN(41) - 20,
+			Left:       1 + rng.Float64()*2879,
+			Possession: rng.IntN(3) - 1,
+			Pregame:    0.2 + 0.6*rng.Float64(),
+		}
+		samples = append(samples, Sample{State: s, HomeWon: rng.Float64() < want.Predict(s)})
+	}
+
+	got, err := Fit(samples)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Events != len(samples) {
+		t.Errorf("fit on %d events, want %d", got.Events, len(samples))
+	}
+	w, g := want.coefficients(), got.coefficients()
+	names := []string{"intercept", "margin", "scaled_margin", "scaled_possession", "pregame"}
+	for i := range w {
+		if math.Abs(w[i]-g[i]) > 0.08 {
+			t.Errorf("%s = %.3f, want %.3f", names[i], g[i], w[i])
+		}
+	}
+}
+
+func TestFitSkipsDecidedStates(t *testing.T) {
+	samples := []Sample{
+		{State: State{Margin: 3, Left: 0}, HomeWon: true},
+		{State: State{Margin: -2, Left: 0}, HomeWon: false},
+	}
+	if _, err := Fit(samples); err == nil {
+		t.Error("fit with only decided states succeeded")
+	}
+}
+
+func TestSolve(t *testing.T) {
+	a := [5][5]float64{
+		{0, 2, 0, 0, 0}, // needs a pivot
+		{1, 0, 0, 0, 0},
+		{0, 0, 4, 1, 0},
+		{0, 0, 1, 3, 0},
+		{0, 0, 0, 0, 5},
+	}
+	want := [5]float64{1, 2, 3, 4, 5}
+	var b [5]float64
+	for i := range a {
+		for j := range a[i] {
+			b[i] += a[i][j] * want[j]
+		}
+	}
+	got, ok := solve(a, b)
+	if !ok {
+		t.Fatal("solve failed")
+	}
+	for i := range want {
+		if math.Abs(got[i]-want[i]) > 1e-9 {
+			t.Errorf("x[%d] = %v, want %v", i, got[i], want[i])
+		}
+	}
+	if _, ok := solve([5][5]float64{}, b); ok {
+		t.Error("solved a singular system")
+	}
+}
+
+func TestPossession(t *testing.T) {
+	yes, no := true, false
+	tests := []struct {
+		name   string
+		e      pbp.Event
+		before int
+		want   int
+	}{
+		{"made shot", pbp.Event{Team: "NYK", Home: true, Type: pbp.TypeShot, Made: &yes}, 1, -1},
+		{"missed shot", pbp.Event{Team: "NYK", Home: true, Type: pbp.TypeShot, Made: &no}, 1, 0},
+		{"first of two made", pbp.Event{Team: "BOS", Type: pbp.TypeFreeThrow, Detail: "1 of 2", Made: &yes}, 0, -1},
+		{"first of two missed", pbp.Event{Team: "BOS", Type: pbp.TypeFreeThrow, Detail: "1 of 2", Made: &no}, 0, -1},
+		{"second of two made", pbp.Event{Team: "BOS", Type: pbp.TypeFreeThrow, Detail: "2 of 2", Made: &yes}, -1, 1},
+		{"second of two missed", pbp.Event{Team: "BOS", Type: pbp.TypeFreeThrow, Detail: "2 of 2", Made: &no}, -1, 0},
+		{"flagrant keeps the ball", pbp.Event{Team: "BOS", Type: pbp.TypeFreeThrow, Detail: "flagrant 2 of 2", Made: &yes}, -1, -1},
+		{"technical", pbp.Event{Team: "BOS", Type: pbp.TypeFreeThrow, Detail: "technical", Made: &yes}, 1, 1},
+		{"turnover", pbp.Event{Team: "BOS", Type: pbp.TypeTurnover}, -1, 1},
+		{"defensive rebound", pbp.Event{Team: "NYK", Home: true, Type: pbp.TypeRebound, Detail: "defensive"}, 0, 1},
+		{"foul", pbp.Event{Team: "NYK", Home: true, Type: pbp.TypeFoul}, -1, -1},
+		{"period start", pbp.Event{Type: pbp.TypePeriodStart}, 1, 0},
+	}
+	for _, tt := range tests {
+		if got := possession(tt.e, tt.before); got != tt.want {
+			t.Errorf("%s: possession = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPredict(t *testing.T) {
+	m := Model{Margin: 0.02, ScaledMargin: 0.9, ScaledPossession: 0.8, Pregame: 1}
+	if p := m.Predict(State{Margin: 0, Left: 2880, Pregame: 0.64}); math.Abs(p-0.64) > 1e-9 {
+		t.Errorf("tip-off = %.3f, want the pregame 0.640", p)
+	}
+	if p := m.Predict(State{Margin: 1, Left: 0}); p != 1 {
+		t.Errorf("home win at the buzzer = %v, want 1", p)
+	}
+	if p := m.Predict(State{Margin: -1, Left: 0}); p != 0 {
+		t.Errorf("home loss at the buzzer = %v, want 0", p)
+	}
+	early := m.Predict(State{Margin: 5, Left: 2000, Pregame: 0.5})
+	late := m.Predict(State{Margin: 5, Left: 60, Pregame: 0.5})
+	if !(0.5 < early && early < late) {
+		t.Errorf("a 5-point lead is worth %.3f early and %.3f late", early, late)
+	}
+	if m.Predict(State{Left: 30, Possession: 1, Pregame: 0.5}) <= 0.5 {
+		t.Error("the ball in a tied game is not worth anything")
+	}
+}
+
+func TestCalibrated(t *testing.T) {
+	p := pbp.PlayByPlay{GameID: "202403110NYK", Away: "BOS", Home: "NYK"}
+	if s := Default.ForGame(p, 0.5); s.Calibrated != (Default.Games > 0) {
+		t.Errorf("embedded model fit on %d games: calibrated = %v", Default.Games, s.Calibrated)
+	}
+	if s := (Model{}).ForGame(p, 0.5); s.Calibrated {
+		t.Error("an unfit model is flagged calibrated")
+	}
+	if s := (Model{Games: 1200}).ForGame(p, 0.5); !s.Calibrated || s.ModelGames != 1200 {
+		t.Errorf("fit model: calibrated = %v, model_games = %d", s.Calibrated, s.ModelGames)
+	}
+}
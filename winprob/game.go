@@ -0,0 +1,164 @@
+package winprob
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/umanchanda/NBA-API/franchise"
+	"github.com/umanchanda/NBA-API/pbp"
+	"github.com/umanchanda/NBA-API/ratings"
+)
+
+// Point is the home win probability after one play.
+type Point struct {
+	Seq         int     `json:"seq"`
+	Period      int     `json:"period"`
+	Clock       string  `json:"clock"`
+	Elapsed     float64 `json:"elapsed"` // seconds played
+	AwayScore   int     `json:"away_score"`
+	HomeScore   int     `json:"home_score"`
+	Possession  string  `json:"possession,omitempty"` // team with the ball, if known
+	HomeWinProb float64 `json:"home_win_prob"`
+	Description string  `json:"description"`
+}
+
+// Series is the /api/game/{id}/winprob response. Calibrated is false while
+// the model is the hand-set default rather than one fit on stored games.
+type Series struct {
+	GameID             string  `json:"game_id"`
+	Away               string  `json:"away"`
+	Home               string  `json:"home"`
+	PregameHomeWinProb float64 `json:"pregame_home_win_prob"`
+	ModelGames         int     `json:"model_games"`
+	Calibrated         bool    `json:"calibrated"`
+	Points             []Point `json:"points"`
+}
+
+var freeThrowOf = regexp.MustCompile(`(\d) of (\d)$`)
+
+// side is 1 for the home team and -1 for the away team.
+func side(home bool) int {
+	if home {
+		return 1
+	}
+	return -1
+}
+
+// possession returns who has the ball after e, given who had it before.
+// The ball is loose, 0, after a missed shot or last free throw until it is
+// rebounded, and at the start of a period.
+func possession(e pbp.Event, before int) int {
+	if e.Team == "" {
+		if e.Type == pbp.TypePeriodStart {
+			return 0
+		}
+		return before
+	}
+	own := side(e.Home)
+	switch e.Type {
+	case pbp.TypeShot:
+		if e.Made != nil && *e.Made {
+			return -own
+		}
+		return 0
+	case pbp.TypeFreeThrow:
+		m := freeThrowOf.FindStringSubmatch(e.Detail)
+		switch {
+		case m == nil:
+			// Technical free throws don't change possession.
+			return before
+		case m[1] != m[2], strings.Contains(e.Detail, "flagrant"), strings.Contains(e.Detail, "clear path"):
+			return own
+		case e.Made != nil && *e.Made:
+			return -own
+		}
+		return 0
+	case pbp.TypeRebound:
+		return own
+	case pbp.TypeTurnover:
+		return -own
+	}
+	return before
+}
+
+// left returns the seconds left in regulation at e, or in its overtime.
+func left(e pbp.Event) float64 {
+	if e.Period > 4 {
+		return e.Remaining
+	}
+	return float64(4-e.Period)*pbp.PeriodLength(e.Period) + e.Remaining
+}
+
+// States returns the game state after every play, given the pregame home
+// win probability.
+func States(p pbp.PlayByPlay, pregame float64) []State {
+	states := make([]State, len(p.Events))
+	ball := 0
+	for i, e := range p.Events {
+		ball = possession(e, ball)
+		states[i] = State{
+			Margin:     e.HomeScore - e.AwayScore,
+			Left:       left(e),
+			Possession: ball,
+			Pregame:    pregame,
+		}
+	}
+	return states
+}
+
+// Pregame returns the home team's pregame Elo win probability, rating
+// every stored result before the game's date.
+func Pregame(db *sql.DB, p pbp.PlayByPlay) (float64, error) {
+	date, err := time.Parse("20060102", p.GameID[:8])
+	if err != nil {
+		return 0, fmt.Errorf("%s: no date in the game id", p.GameID)
+	}
+	e, err := ratings.Through(db, date)
+	if err != nil {
+		return 0, err
+	}
+	away, okA := franchise.Lookup(p.Away)
+	home, okH := franchise.Lookup(p.Home)
+	if !okA || !okH {
+		return ratings.WinProbability(ratings.InitialElo, ratings.InitialElo), nil
+	}
+	return ratings.WinProbability(e.Rating(home.ID), e.Rating(away.ID)), nil
+}
+
+// ForGame returns the home win probability after every play of a game.
+func (m Model) ForGame(p pbp.PlayByPlay, pregame float64) Series {
+	s := Series{
+		GameID:             p.GameID,
+		Away:               p.Away,
+		Home:               p.Home,
+		PregameHomeWinProb: math.Round(pregame*1000) / 1000,
+		ModelGames:         m.Games,
+		Calibrated:         m.Games > 0,
+		Points:             make([]Point, len(p.Events)),
+	}
+	for i, st := range States(p, pregame) {
+		e := p.Events[i]
+		pt := Point{
+			Seq:         e.Seq,
+			Period:      e.Period,
+			Clock:       e.Clock,
+			Elapsed:     e.Elapsed(),
+			AwayScore:   e.AwayScore,
+			HomeScore:   e.HomeScore,
+			HomeWinProb: math.Round(m.Predict(st)*1000) / 1000,
+			Description: e.Description,
+		}
+		switch st.Possession {
+		case 1:
+			pt.Possession = p.Home
+		case -1:
+			pt.Possession = p.Away
+		}
+		s.Points[i] = pt
+	}
+	return s
+}
@@ -0,0 +1,99 @@
+// Package winprob estimates the home team's chance of winning at every
+// play of a game from the score margin, the time left, who has the ball and
+// the pregame Elo forecast. The model is a logistic regression fit offline
+// by cmd/fitwinprob and embedded from model.json.
+package winprob
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+//go:embed model.json
+var modelJSON []byte
+
+// Default is the embedded model. The committed model.json has not been fit
+// yet: its coefficients are set by hand so that a tied game starts at the
+// pregame forecast and late leads count for more, and Games is 0 until
+// cmd/fitwinprob is run on backfilled play-by-play.
+var Default = mustLoad(modelJSON)
+
+// Model holds the logistic regression coefficients. Games is the number of
+// games the model was fit on, 0 for the hand-set defaults.
+type Model struct {
+	Games            int     `json:"games"`
+	Events           int     `json:"events"`
+	Intercept        float64 `json:"intercept"`
+	Margin           float64 `json:"margin"`
+	ScaledMargin     float64 `json:"scaled_margin"`
+	ScaledPossession float64 `json:"scaled_possession"`
+	Pregame          float64 `json:"pregame"`
+}
+
+// Load decodes a model from JSON.
+func Load(data []byte) (Model, error) {
+	var m Model
+	if err := json.Unmarshal(data, &m); err != nil {
+		return Model{}, fmt.Errorf("decoding win probability model: %w", err)
+	}
+	return m, nil
+}
+
+func mustLoad(data []byte) Model {
+	m, err := Load(data)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// State is the game as of one play.
+type State struct {
+	Margin     int     // home score minus away score
+	Left       float64 // seconds left in regulation, or in the overtime
+	Possession int     // 1 when the home team has the ball, -1 the away team, 0 unknown
+	Pregame    float64 // pregame home win probability
+}
+
+// features returns the model inputs for s: the margin, the margin and
+// possession scaled up as time runs out, and the pregame log-odds fading
+// out over the game. The first is the intercept's constant.
+func (s State) features() [5]float64 {
+	minutes := s.Left / 60
+	scale := 1 / math.Sqrt(minutes+1)
+	p := math.Min(math.Max(s.Pregame, 0.001), 0.999)
+	return [5]float64{
+		1,
+		float64(s.Margin),
+		float64(s.Margin) * scale,
+		float64(s.Possession) * scale,
+		math.Log(p/(1-p)) * minutes / 48,
+	}
+}
+
+func (m Model) coefficients() [5]float64 {
+	return [5]float64{m.Intercept, m.Margin, m.ScaledMargin, m.ScaledPossession, m.Pregame}
+}
+
+func sigmoid(z float64) float64 {
+	return 1 / (1 + math.Exp(-z))
+}
+
+// Predict returns the home team's chance of winning from s. A game with no
+// time left and a margin is decided.
+func (m Model) Predict(s State) float64 {
+	if s.Left <= 0 && s.Margin != 0 {
+		if s.Margin > 0 {
+			return 1
+		}
+		return 0
+	}
+	x, w := s.features(), m.coefficients()
+	var z float64
+	for i := range x {
+		z += w[i] * x[i]
+	}
+	return sigmoid(z)
+}